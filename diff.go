@@ -0,0 +1,39 @@
+// Copyright 2025 Adevinta
+
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/adevinta/ghe-reposec/internal/diff"
+	"github.com/adevinta/ghe-reposec/internal/output"
+)
+
+// runDiff compares two result files and prints the differences between
+// them. It returns the process exit code.
+func runDiff(args []string) int {
+	if len(args) != 2 {
+		fmt.Println("usage: ghe-reposec diff <previous results file> <current results file>")
+		return 2
+	}
+
+	previous, err := output.Read(args[0])
+	if err != nil {
+		fmt.Printf("failed to read previous results: %v\n", err)
+		return 1
+	}
+	current, err := output.Read(args[1])
+	if err != nil {
+		fmt.Printf("failed to read current results: %v\n", err)
+		return 1
+	}
+
+	report := diff.Compare(previous, current)
+	if err := report.Write(os.Stdout); err != nil {
+		fmt.Printf("failed to write diff report: %v\n", err)
+		return 1
+	}
+
+	return 0
+}
@@ -0,0 +1,86 @@
+// Copyright 2025 Adevinta
+
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/adevinta/ghe-reposec/internal/store"
+)
+
+// runHistory prints compliance trend tables from the results store. It
+// returns the process exit code.
+func runHistory(args []string) int {
+	fs := flag.NewFlagSet("history", flag.ContinueOnError)
+	storePath := fs.String("store", os.Getenv("REPOSEC_STORE_PATH"), "path of the SQLite results store")
+	by := fs.String("by", "org", "trend dimension: org, control or repository")
+	runs := fs.Int("runs", 10, "number of most recent runs to include")
+	filter := fs.String("filter", "", "only include the provided organization, control or repositories matching it")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+
+	st, err := store.OpenReadOnly(*storePath)
+	if err != nil {
+		fmt.Printf("failed to open store: %v\n", err)
+		return 1
+	}
+	defer st.Close()
+
+	tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	switch *by {
+	case "org":
+		trends, err := st.OrganizationTrends(*runs, *filter)
+		if err != nil {
+			fmt.Printf("failed to query organization trends: %v\n", err)
+			return 1
+		}
+		fmt.Fprintln(tw, "ORGANIZATION\tRUN\tSTARTED\tREPOSITORIES\tWITH CONTROLS\tWITHOUT CONTROLS\tERRORS\tCOMPLIANCE")
+		for _, t := range trends {
+			compliance := 0.0
+			if scanned := t.WithControls + t.WithoutControls; scanned > 0 {
+				compliance = 100 * float64(t.WithControls) / float64(scanned)
+			}
+			fmt.Fprintf(tw, "%s\t%s\t%s\t%d\t%d\t%d\t%d\t%.1f%%\n",
+				t.Organization, t.RunID, t.StartedAt.Format(time.DateTime),
+				t.Repositories, t.WithControls, t.WithoutControls, t.Errors, compliance)
+		}
+	case "control":
+		trends, err := st.ControlTrends(*runs, *filter)
+		if err != nil {
+			fmt.Printf("failed to query control trends: %v\n", err)
+			return 1
+		}
+		fmt.Fprintln(tw, "CONTROL\tRUN\tSTARTED\tREPOSITORIES")
+		for _, t := range trends {
+			fmt.Fprintf(tw, "%s\t%s\t%s\t%d\n", t.Control, t.RunID, t.StartedAt.Format(time.DateTime), t.Repositories)
+		}
+	case "repository":
+		trends, err := st.RepositoryTrends(*runs, *filter)
+		if err != nil {
+			fmt.Printf("failed to query repository trends: %v\n", err)
+			return 1
+		}
+		fmt.Fprintln(tw, "REPOSITORY\tRUN\tSTARTED\tCONTROL IN PLACE\tCONTROLS\tERROR")
+		for _, t := range trends {
+			fmt.Fprintf(tw, "%s\t%s\t%s\t%t\t%s\t%s\n",
+				t.Summary.Repository, t.RunID, t.StartedAt.Format(time.DateTime),
+				t.Summary.ControlInPlace, strings.Join(t.Summary.Controls, "#"), t.Summary.Error)
+		}
+	default:
+		fmt.Printf("unsupported history dimension: %s\n", *by)
+		return 2
+	}
+
+	if err := tw.Flush(); err != nil {
+		fmt.Printf("failed to write history: %v\n", err)
+		return 1
+	}
+
+	return 0
+}
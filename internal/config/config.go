@@ -4,6 +4,9 @@
 package config
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
 	"log/slog"
 	"os"
 	"strings"
@@ -46,32 +49,156 @@ type LavaConfig struct {
 // MetricsConfig represents the metrics configuration.
 type MetricsConfig struct {
 	Enabled   bool     `env:"METRICS_ENABLED" envDefault:"false"`
+	Backend   string   `env:"METRICS_BACKEND" envDefault:"statsd"`
 	Address   string   `env:"METRICS_ADDRESS" envDefault:"localhost:8125"`
 	Namespace string   `env:"METRICS_NAMESPACE" envDefault:"ghereposec"`
 	Tags      []string `env:"METRICS_TAGS" envSeparator:"," envDefault:"ghereposec:metrics"`
+
+	ControlsByOrganization bool `env:"METRICS_CONTROLS_BY_ORGANIZATION" envDefault:"false"`
+
+	PrometheusListenAddress  string `env:"METRICS_PROMETHEUS_LISTEN_ADDRESS" envDefault:":9102"`
+	PrometheusPushgatewayURL string `env:"METRICS_PROMETHEUS_PUSHGATEWAY_URL"`
+	PrometheusJob            string `env:"METRICS_PROMETHEUS_JOB" envDefault:"ghereposec"`
+}
+
+// RemediationConfig represents the remediation configuration.
+type RemediationConfig struct {
+	Issues        bool   `env:"REMEDIATION_ISSUES" envDefault:"false"`
+	IssueTitle    string `env:"REMEDIATION_ISSUE_TITLE" envDefault:"Missing security controls"`
+	IssueLabel    string `env:"REMEDIATION_ISSUE_LABEL" envDefault:"reposec"`
+	IssueTemplate string `env:"REMEDIATION_ISSUE_TEMPLATE"`
+	GuidanceURL   string `env:"REMEDIATION_GUIDANCE_URL"`
+	Concurrency   int    `env:"REMEDIATION_CONCURRENCY" envDefault:"5"`
+
+	PullRequests   bool   `env:"REMEDIATION_PULL_REQUESTS" envDefault:"false"`
+	PRTemplateFile string `env:"REMEDIATION_PR_TEMPLATE_FILE"`
+	PRTargetPath   string `env:"REMEDIATION_PR_TARGET_PATH" envDefault:".github/workflows/security.yml"`
+	PRBranch       string `env:"REMEDIATION_PR_BRANCH" envDefault:"reposec/baseline-security"`
+	PRTitle        string `env:"REMEDIATION_PR_TITLE" envDefault:"Add baseline security workflow"`
+}
+
+// OwnersConfig represents the repository ownership resolution configuration.
+type OwnersConfig struct {
+	Enabled  bool     `env:"OWNERS_ENABLED" envDefault:"false"`
+	Sources  []string `env:"OWNERS_SOURCES" envSeparator:"," envDefault:"codeowners,teams"`
+	Property string   `env:"OWNERS_PROPERTY" envDefault:"owner"`
+}
+
+// CollectorsConfig represents the repository data collectors configuration.
+type CollectorsConfig struct {
+	SecurityAnalysis bool `env:"COLLECT_SECURITY_ANALYSIS" envDefault:"false"`
+	BranchProtection bool `env:"COLLECT_BRANCH_PROTECTION" envDefault:"false"`
+	Alerts           bool `env:"COLLECT_ALERTS" envDefault:"false"`
+	Workflows        bool `env:"COLLECT_WORKFLOWS" envDefault:"false"`
+	Access           bool `env:"COLLECT_ACCESS" envDefault:"false"`
+	Webhooks         bool `env:"COLLECT_WEBHOOKS" envDefault:"false"`
+	Files            bool `env:"COLLECT_FILES" envDefault:"false"`
+
+	AlertThresholds map[string]int `env:"ALERT_THRESHOLDS" envSeparator:"," envKeyValSeparator:":"`
+
+	MaxOutsideCollaborators int `env:"ACCESS_MAX_OUTSIDE_COLLABORATORS" envDefault:"-1"`
+	MaxWriteDeployKeys      int `env:"ACCESS_MAX_WRITE_DEPLOY_KEYS" envDefault:"-1"`
+	MaxDeployKeyAgeDays     int `env:"ACCESS_MAX_DEPLOY_KEY_AGE_DAYS" envDefault:"-1"`
+
+	WebhooksAllowedDomains []string `env:"WEBHOOKS_ALLOWED_DOMAINS" envSeparator:","`
+}
+
+// NotifyConfig represents the owner notifications configuration.
+type NotifyConfig struct {
+	SlackWebhooksFile string `env:"NOTIFY_SLACK_WEBHOOKS_FILE"`
+
+	EmailRecipientsFile string `env:"NOTIFY_EMAIL_RECIPIENTS_FILE"`
+	EmailFrom           string `env:"NOTIFY_EMAIL_FROM"`
+	EmailSubject        string `env:"NOTIFY_EMAIL_SUBJECT" envDefault:"Security controls digest"`
+	EmailTextTemplate   string `env:"NOTIFY_EMAIL_TEXT_TEMPLATE"`
+	EmailHTMLTemplate   string `env:"NOTIFY_EMAIL_HTML_TEMPLATE"`
+	EmailDryRunPath     string `env:"NOTIFY_EMAIL_DRY_RUN_PATH"`
+	SMTPAddress         string `env:"NOTIFY_SMTP_ADDRESS"`
+	SMTPUsername        string `env:"NOTIFY_SMTP_USERNAME"`
+	SMTPPassword        string `env:"NOTIFY_SMTP_PASSWORD"`
+}
+
+// TracingConfig represents the OpenTelemetry tracing configuration.
+type TracingConfig struct {
+	Enabled     bool   `env:"TRACING_ENABLED" envDefault:"false"`
+	Endpoint    string `env:"TRACING_ENDPOINT"`
+	Insecure    bool   `env:"TRACING_INSECURE" envDefault:"false"`
+	ServiceName string `env:"TRACING_SERVICE_NAME" envDefault:"ghe-reposec"`
+}
+
+// PostgresConfig represents the PostgreSQL output configuration.
+type PostgresConfig struct {
+	DSN             string `env:"POSTGRES_DSN"`
+	RunsTable       string `env:"POSTGRES_RUNS_TABLE" envDefault:"reposec_runs"`
+	ResultsTable    string `env:"POSTGRES_RESULTS_TABLE" envDefault:"reposec_results"`
+	MigrationsTable string `env:"POSTGRES_MIGRATIONS_TABLE" envDefault:"reposec_schema_migrations"`
+}
+
+// S3Config represents the S3 compatible object storage configuration.
+type S3Config struct {
+	Endpoint        string `env:"S3_ENDPOINT" envDefault:"s3.amazonaws.com"`
+	Region          string `env:"S3_REGION"`
+	Bucket          string `env:"S3_BUCKET"`
+	Prefix          string `env:"S3_PREFIX" envDefault:"reposec"`
+	AccessKeyID     string `env:"S3_ACCESS_KEY_ID"`
+	SecretAccessKey string `env:"S3_SECRET_ACCESS_KEY"`
+	UseSSL          bool   `env:"S3_USE_SSL" envDefault:"true"`
 }
 
 // Config represents the ghe-reposec configuration.
 type Config struct {
-	LogLevel       string `env:"LOG_LEVEL" envDefault:"info"`
-	LogOutput      string `env:"LOG_OUTPUT" envDefault:"stdout"`
-	LogFormat      string `env:"LOG_OUTPUT_FORMAT" envDefault:"text"`
-	TargetOrg      string `env:"TARGET_ORG"`
-	OutputFilePath string `env:"OUTPUT_FILE" envDefault:"/tmp/reposec.csv"`
-	OutputFormat   string `env:"OUTPUT_FORMAT" envDefault:"csv"`
+	LogLevel             string             `env:"LOG_LEVEL" envDefault:"info"`
+	LogOutput            string             `env:"LOG_OUTPUT" envDefault:"stdout"`
+	LogFormat            string             `env:"LOG_OUTPUT_FORMAT" envDefault:"text"`
+	TargetOrg            string             `env:"TARGET_ORG"`
+	OutputFilePath       string             `env:"OUTPUT_FILE" envDefault:"/tmp/reposec.csv"`
+	OutputFormat         string             `env:"OUTPUT_FORMAT" envDefault:"csv"`
+	OutputStreamFilePath string             `env:"OUTPUT_STREAM_FILE"`
+	StorePath            string             `env:"STORE_PATH"`
+	PolicyFilePath       string             `env:"POLICY_FILE"`
+	WaiversFilePath      string             `env:"WAIVERS_FILE"`
+	ScoreWeights         map[string]float64 `env:"SCORE_WEIGHTS" envSeparator:"," envKeyValSeparator:":"`
+	ComplianceControls   []string           `env:"COMPLIANCE_CONTROLS" envSeparator:","`
 
-	GHECfg     GHEConfig
-	LavaCfg    LavaConfig
-	MetricsCfg MetricsConfig
+	GHECfg         GHEConfig
+	LavaCfg        LavaConfig
+	MetricsCfg     MetricsConfig
+	TracingCfg     TracingConfig
+	RemediationCfg RemediationConfig
+	OwnersCfg      OwnersConfig
+	CollectorsCfg  CollectorsConfig
+	NotifyCfg      NotifyConfig
+	PostgresCfg    PostgresConfig
+	S3Cfg          S3Config
 }
 
 // Redacted returns a secret redacted version of the configuration.
 func (c Config) Redacted() Config {
 	c.GHECfg.Token = "REDACTED"
 	c.LavaCfg.Token = "REDACTED"
+	if c.PostgresCfg.DSN != "" {
+		c.PostgresCfg.DSN = "REDACTED"
+	}
+	if c.S3Cfg.SecretAccessKey != "" {
+		c.S3Cfg.SecretAccessKey = "REDACTED"
+	}
+	if c.NotifyCfg.SMTPPassword != "" {
+		c.NotifyCfg.SMTPPassword = "REDACTED"
+	}
 	return c
 }
 
+// Hash returns a hash of the redacted configuration. It allows to identify
+// executions that share the same configuration.
+func (c Config) Hash() string {
+	data, err := json.Marshal(c.Redacted())
+	if err != nil {
+		return ""
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
 // Load parses the configuration from the environment.
 func Load() (*Config, error) {
 	var cfg Config
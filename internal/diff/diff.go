@@ -0,0 +1,128 @@
+// Copyright 2025 Adevinta
+
+// Package diff compares the results of two ghe-reposec executions.
+package diff
+
+import (
+	"fmt"
+	"io"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/adevinta/ghe-reposec/internal/lava"
+)
+
+// Change represents the change of a repository between two executions.
+type Change struct {
+	Repository string
+	Controls   []string
+	Error      string
+}
+
+// Report represents the differences between two executions.
+type Report struct {
+	LostControls   []Change
+	GainedControls []Change
+	Appeared       []Change
+	Disappeared    []Change
+	NewErrors      []Change
+}
+
+// Compare compares the summaries of two executions.
+func Compare(previous, current []lava.Summary) Report {
+	prev := index(previous)
+	curr := index(current)
+
+	r := Report{}
+	for repo, c := range curr {
+		p, ok := prev[repo]
+		if !ok {
+			r.Appeared = append(r.Appeared, Change{Repository: repo, Controls: c.Controls, Error: c.Error})
+			continue
+		}
+		if c.Error != "" {
+			if p.Error == "" {
+				r.NewErrors = append(r.NewErrors, Change{Repository: repo, Error: c.Error})
+			}
+			continue
+		}
+		// Controls can not be compared if the previous scan failed.
+		if p.Error != "" {
+			continue
+		}
+		if lost := missing(p.Controls, c.Controls); len(lost) > 0 || (p.ControlInPlace && !c.ControlInPlace) {
+			r.LostControls = append(r.LostControls, Change{Repository: repo, Controls: lost})
+		}
+		if gained := missing(c.Controls, p.Controls); len(gained) > 0 || (!p.ControlInPlace && c.ControlInPlace) {
+			r.GainedControls = append(r.GainedControls, Change{Repository: repo, Controls: gained})
+		}
+	}
+	for repo, p := range prev {
+		if _, ok := curr[repo]; !ok {
+			r.Disappeared = append(r.Disappeared, Change{Repository: repo, Controls: p.Controls, Error: p.Error})
+		}
+	}
+
+	for _, changes := range [][]Change{r.LostControls, r.GainedControls, r.Appeared, r.Disappeared, r.NewErrors} {
+		sort.Slice(changes, func(i, j int) bool { return changes[i].Repository < changes[j].Repository })
+	}
+
+	return r
+}
+
+// Write writes a human readable version of the report.
+func (r Report) Write(w io.Writer) error {
+	sections := []struct {
+		title   string
+		changes []Change
+	}{
+		{"Repositories that lost controls", r.LostControls},
+		{"Repositories that started erroring", r.NewErrors},
+		{"Repositories that gained controls", r.GainedControls},
+		{"New repositories", r.Appeared},
+		{"Disappeared repositories", r.Disappeared},
+	}
+
+	for _, s := range sections {
+		if _, err := fmt.Fprintf(w, "%s (%d):\n", s.title, len(s.changes)); err != nil {
+			return err
+		}
+		for _, c := range s.changes {
+			line := "  " + c.Repository
+			if len(c.Controls) > 0 {
+				line += ": " + strings.Join(c.Controls, ", ")
+			}
+			if c.Error != "" {
+				line += fmt.Sprintf(" (error: %s)", c.Error)
+			}
+			if _, err := fmt.Fprintln(w, line); err != nil {
+				return err
+			}
+		}
+		if _, err := fmt.Fprintln(w); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func index(summary []lava.Summary) map[string]lava.Summary {
+	m := make(map[string]lava.Summary, len(summary))
+	for _, s := range summary {
+		m[s.Repository] = s
+	}
+	return m
+}
+
+// missing returns the elements of a that are not in b.
+func missing(a, b []string) []string {
+	diff := []string{}
+	for _, v := range a {
+		if !slices.Contains(b, v) && !slices.Contains(diff, v) {
+			diff = append(diff, v)
+		}
+	}
+	return diff
+}
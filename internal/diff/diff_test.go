@@ -0,0 +1,82 @@
+// Copyright 2025 Adevinta
+
+package diff
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/adevinta/ghe-reposec/internal/lava"
+)
+
+func TestCompare(t *testing.T) {
+	tests := []struct {
+		name     string
+		previous []lava.Summary
+		current  []lava.Summary
+		want     Report
+	}{
+		{
+			name:     "unchanged",
+			previous: []lava.Summary{{Repository: "a", Controls: []string{"sast"}, ControlInPlace: true}},
+			current:  []lava.Summary{{Repository: "a", Controls: []string{"sast"}, ControlInPlace: true}},
+			want:     Report{},
+		},
+		{
+			name: "lost and gained controls",
+			previous: []lava.Summary{
+				{Repository: "a", Controls: []string{"sast", "sca"}, ControlInPlace: true},
+				{Repository: "b", Controls: []string{"sast"}, ControlInPlace: true},
+			},
+			current: []lava.Summary{
+				{Repository: "a", Controls: []string{"sast"}, ControlInPlace: true},
+				{Repository: "b", Controls: []string{"sast", "sca"}, ControlInPlace: true},
+			},
+			want: Report{
+				LostControls:   []Change{{Repository: "a", Controls: []string{"sca"}}},
+				GainedControls: []Change{{Repository: "b", Controls: []string{"sca"}}},
+			},
+		},
+		{
+			name:     "lost control in place",
+			previous: []lava.Summary{{Repository: "a", Controls: []string{"license"}, ControlInPlace: true}},
+			current:  []lava.Summary{{Repository: "a", Controls: []string{"license"}}},
+			want:     Report{LostControls: []Change{{Repository: "a", Controls: []string{}}}},
+		},
+		{
+			name:     "appeared and disappeared",
+			previous: []lava.Summary{{Repository: "a", Controls: []string{"sast"}}},
+			current:  []lava.Summary{{Repository: "b", Error: "scan failed"}},
+			want: Report{
+				Appeared:    []Change{{Repository: "b", Error: "scan failed"}},
+				Disappeared: []Change{{Repository: "a", Controls: []string{"sast"}}},
+			},
+		},
+		{
+			name: "new errors",
+			previous: []lava.Summary{
+				{Repository: "a", Controls: []string{"sast"}, ControlInPlace: true},
+				{Repository: "b", Error: "scan failed"},
+			},
+			current: []lava.Summary{
+				{Repository: "a", Error: "scan failed"},
+				{Repository: "b", Error: "scan failed again"},
+			},
+			want: Report{NewErrors: []Change{{Repository: "a", Error: "scan failed"}}},
+		},
+		{
+			name:     "recovered from error",
+			previous: []lava.Summary{{Repository: "a", Error: "scan failed"}},
+			current:  []lava.Summary{{Repository: "a", Controls: []string{"sast"}, ControlInPlace: true}},
+			want:     Report{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Compare(tt.previous, tt.current)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("unexpected report:\ngot:  %+v\nwant: %+v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,135 @@
+// Copyright 2025 Adevinta
+
+package github
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	gh "github.com/google/go-github/v67/github"
+)
+
+// Access finding rules.
+const (
+	FindingOutsideCollaborator = "outside-collaborator"
+	FindingWriteDeployKey      = "write-deploy-key"
+)
+
+// Collaborator is an outside collaborator with write access to a repository.
+type Collaborator struct {
+	Login      string
+	Permission string
+}
+
+// DeployKey is a deploy key with write access to a repository.
+type DeployKey struct {
+	ID        int64
+	Title     string
+	CreatedAt time.Time
+}
+
+// Age returns the age of the deploy key in days.
+func (k DeployKey) Age(now time.Time) int {
+	return int(now.Sub(k.CreatedAt).Hours() / 24)
+}
+
+// Access audits the outside collaborators and the deploy keys with write
+// access to the repositories.
+type Access struct {
+	cli *Client
+}
+
+// NewAccess creates a new access collector.
+func NewAccess(cli *Client) *Access {
+	return &Access{cli: cli}
+}
+
+// Name returns the name of the collector.
+func (a *Access) Name() string {
+	return "access"
+}
+
+// Collect sets the outside collaborators and the deploy keys with write
+// access to the repository in its OutsideCollaborators and DeployKeys fields,
+// and reports them as findings. AccessAudited is only set when both could be
+// listed.
+func (a *Access) Collect(ctx context.Context, repo *Repository) error {
+	owner, name, ok := strings.Cut(repo.FullName, "/")
+	if !ok {
+		return fmt.Errorf("invalid repository name: %s", repo.FullName)
+	}
+	ctx = context.WithValue(ctx, gh.SleepUntilPrimaryRateLimitResetWhenRateLimited, true)
+
+	collaborators := []Collaborator{}
+	// The permission filter is applied on the client side as it is not
+	// supported by every GitHub Enterprise version.
+	copts := &gh.ListCollaboratorsOptions{
+		Affiliation: "outside",
+		ListOptions: gh.ListOptions{PerPage: 100},
+	}
+	for {
+		t := time.Now()
+		users, resp, err := a.cli.client.Repositories.ListCollaborators(ctx, owner, name, copts)
+		a.cli.apiLatency("list_collaborators", owner, t, err)
+		if err != nil {
+			return fmt.Errorf("failed to list outside collaborators: %w", err)
+		}
+		for _, u := range users {
+			p := permission(u.Permissions)
+			if p == "unknown" {
+				continue
+			}
+			collaborators = append(collaborators, Collaborator{Login: u.GetLogin(), Permission: p})
+		}
+		if resp.NextPage == 0 {
+			break
+		}
+		copts.Page = resp.NextPage
+	}
+
+	keys := []DeployKey{}
+	kopts := &gh.ListOptions{PerPage: 100}
+	for {
+		t := time.Now()
+		page, resp, err := a.cli.client.Repositories.ListKeys(ctx, owner, name, kopts)
+		a.cli.apiLatency("list_deploy_keys", owner, t, err)
+		if err != nil {
+			return fmt.Errorf("failed to list deploy keys: %w", err)
+		}
+		for _, k := range page {
+			if k.GetReadOnly() {
+				continue
+			}
+			keys = append(keys, DeployKey{ID: k.GetID(), Title: k.GetTitle(), CreatedAt: k.GetCreatedAt().Time})
+		}
+		if resp.NextPage == 0 {
+			break
+		}
+		kopts.Page = resp.NextPage
+	}
+
+	repo.OutsideCollaborators = collaborators
+	repo.DeployKeys = keys
+	repo.AccessAudited = true
+	now := time.Now()
+	for _, c := range collaborators {
+		repo.Findings = append(repo.Findings, FindingOutsideCollaborator+":"+c.Login+":"+c.Permission)
+	}
+	for _, k := range keys {
+		repo.Findings = append(repo.Findings, FindingWriteDeployKey+":"+strconv.FormatInt(k.ID, 10)+":age="+strconv.Itoa(k.Age(now))+"d")
+	}
+	return nil
+}
+
+// permission returns the highest write permission of a collaborator.
+func permission(permissions map[string]bool) string {
+	for _, p := range []string{"admin", "maintain", "push"} {
+		if permissions[p] {
+			return p
+		}
+	}
+	return "unknown"
+}
@@ -0,0 +1,70 @@
+// Copyright 2025 Adevinta
+
+package github
+
+import (
+	"context"
+	"net/http"
+	"slices"
+	"testing"
+
+	"github.com/adevinta/ghe-reposec/internal/config"
+)
+
+func TestAccessCollect(t *testing.T) {
+	keys := func(w http.ResponseWriter, _ *http.Request) {
+		writeJSON(w, []map[string]any{
+			{"id": 1, "title": "deploy", "read_only": false},
+			{"id": 2, "title": "read", "read_only": true},
+		})
+	}
+
+	tests := []struct {
+		name              string
+		collaborators     http.HandlerFunc
+		wantErr           bool
+		wantCollaborators []Collaborator
+	}{
+		{
+			name: "write access",
+			collaborators: func(w http.ResponseWriter, _ *http.Request) {
+				writeJSON(w, []map[string]any{
+					{"login": "admin", "permissions": map[string]bool{"admin": true, "push": true, "pull": true}},
+					{"login": "writer", "permissions": map[string]bool{"push": true, "pull": true}},
+					{"login": "reader", "permissions": map[string]bool{"pull": true}},
+				})
+			},
+			wantCollaborators: []Collaborator{{Login: "admin", Permission: "admin"}, {Login: "writer", Permission: "push"}},
+		},
+		{
+			name: "forbidden",
+			collaborators: func(w http.ResponseWriter, _ *http.Request) {
+				writeStatus(w, http.StatusForbidden, "Must have push access to view repository collaborators.")
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cli := newTestClient(t, config.GHEConfig{}, map[string]http.HandlerFunc{
+				"GET /repos/org/repo/collaborators": tt.collaborators,
+				"GET /repos/org/repo/keys":          keys,
+			})
+
+			repo := Repository{FullName: "org/repo"}
+			err := NewAccess(cli).Collect(context.Background(), &repo)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if repo.AccessAudited == tt.wantErr {
+				t.Errorf("unexpected access audited: %v", repo.AccessAudited)
+			}
+			if !slices.Equal(repo.OutsideCollaborators, tt.wantCollaborators) {
+				t.Errorf("unexpected collaborators: got %v, want %v", repo.OutsideCollaborators, tt.wantCollaborators)
+			}
+			if !tt.wantErr && (len(repo.DeployKeys) != 1 || repo.DeployKeys[0].ID != 1) {
+				t.Errorf("unexpected deploy keys: %v", repo.DeployKeys)
+			}
+		})
+	}
+}
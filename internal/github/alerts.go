@@ -0,0 +1,165 @@
+// Copyright 2025 Adevinta
+
+package github
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+
+	gh "github.com/google/go-github/v67/github"
+)
+
+// Alert tools.
+const (
+	AlertToolCodeScanning   = "code_scanning"
+	AlertToolSecretScanning = "secret_scanning"
+	AlertToolDependabot     = "dependabot"
+)
+
+// featureDisabledMessage matches the error messages returned by GitHub
+// Enterprise when the alerts tool is not enabled in the repository.
+var featureDisabledMessage = regexp.MustCompile(`(?i)disabled|not enabled|must be enabled|no analysis found`)
+
+// SecretScanningSeverity is the severity of the secret scanning alerts, as
+// they do not have one.
+const SecretScanningSeverity = "high"
+
+// Alerts counts the open code scanning, secret scanning and Dependabot alerts
+// of the repositories by severity.
+type Alerts struct {
+	cli *Client
+}
+
+// NewAlerts creates a new alerts collector.
+func NewAlerts(cli *Client) *Alerts {
+	return &Alerts{cli: cli}
+}
+
+// Name returns the name of the collector.
+func (a *Alerts) Name() string {
+	return "alerts"
+}
+
+// Collect sets the number of open alerts of the repository in its Alerts
+// field, keyed by "tool:severity". Tools not enabled in the repository are
+// ignored, while any other error, like missing permissions, is returned so
+// the repository is not reported as having no alerts.
+func (a *Alerts) Collect(ctx context.Context, repo *Repository) error {
+	owner, name, ok := strings.Cut(repo.FullName, "/")
+	if !ok {
+		return fmt.Errorf("invalid repository name: %s", repo.FullName)
+	}
+	ctx = context.WithValue(ctx, gh.SleepUntilPrimaryRateLimitResetWhenRateLimited, true)
+
+	alerts := map[string]int{}
+	for _, count := range []func(context.Context, string, string, map[string]int) error{
+		a.codeScanning,
+		a.secretScanning,
+		a.dependabot,
+	} {
+		if err := count(ctx, owner, name, alerts); err != nil {
+			return err
+		}
+	}
+	repo.Alerts = alerts
+	return nil
+}
+
+func (a *Alerts) codeScanning(ctx context.Context, owner, repo string, alerts map[string]int) error {
+	opts := &gh.AlertListOptions{State: "open", ListOptions: gh.ListOptions{PerPage: 100}}
+	for {
+		t := time.Now()
+		page, resp, err := a.cli.client.CodeScanning.ListAlertsForRepo(ctx, owner, repo, opts)
+		a.cli.apiLatency("list_code_scanning_alerts", owner, t, err)
+		if featureDisabled(err) {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("failed to list code scanning alerts: %w", err)
+		}
+		for _, alert := range page {
+			severity := alert.GetRule().GetSecuritySeverityLevel()
+			if severity == "" {
+				severity = alert.GetRule().GetSeverity()
+			}
+			alerts[alertKey(AlertToolCodeScanning, severity)]++
+		}
+		if resp.NextPage == 0 {
+			return nil
+		}
+		opts.ListOptions.Page = resp.NextPage
+	}
+}
+
+func (a *Alerts) secretScanning(ctx context.Context, owner, repo string, alerts map[string]int) error {
+	opts := &gh.SecretScanningAlertListOptions{State: "open", ListOptions: gh.ListOptions{PerPage: 100}}
+	for {
+		t := time.Now()
+		page, resp, err := a.cli.client.SecretScanning.ListAlertsForRepo(ctx, owner, repo, opts)
+		a.cli.apiLatency("list_secret_scanning_alerts", owner, t, err)
+		if featureDisabled(err) {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("failed to list secret scanning alerts: %w", err)
+		}
+		alerts[alertKey(AlertToolSecretScanning, SecretScanningSeverity)] += len(page)
+		if resp.NextPage == 0 {
+			return nil
+		}
+		opts.ListOptions.Page = resp.NextPage
+	}
+}
+
+func (a *Alerts) dependabot(ctx context.Context, owner, repo string, alerts map[string]int) error {
+	opts := &gh.ListAlertsOptions{State: gh.String("open")}
+	opts.ListOptions.PerPage = 100
+	for {
+		t := time.Now()
+		page, resp, err := a.cli.client.Dependabot.ListRepoAlerts(ctx, owner, repo, opts)
+		a.cli.apiLatency("list_dependabot_alerts", owner, t, err)
+		if featureDisabled(err) {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("failed to list Dependabot alerts: %w", err)
+		}
+		for _, alert := range page {
+			alerts[alertKey(AlertToolDependabot, alert.GetSecurityAdvisory().GetSeverity())]++
+		}
+		// Dependabot alerts are paginated using cursors.
+		if resp.After == "" || len(page) == 0 {
+			return nil
+		}
+		opts.ListCursorOptions.After = resp.After
+	}
+}
+
+// featureDisabled returns whether err reports that the alerts tool is not
+// enabled in the repository. GitHub Enterprise reports it with a not found or
+// a forbidden status depending on the tool, so the message is checked to tell
+// it apart from missing permissions.
+func featureDisabled(err error) bool {
+	var errResp *gh.ErrorResponse
+	if !errors.As(err, &errResp) || errResp.Response == nil {
+		return false
+	}
+	switch errResp.Response.StatusCode {
+	case http.StatusNotFound, http.StatusForbidden:
+		return featureDisabledMessage.MatchString(errResp.Message)
+	default:
+		return false
+	}
+}
+
+func alertKey(tool, severity string) string {
+	if severity == "" {
+		severity = "unknown"
+	}
+	return tool + ":" + strings.ToLower(severity)
+}
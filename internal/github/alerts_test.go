@@ -0,0 +1,76 @@
+// Copyright 2025 Adevinta
+
+package github
+
+import (
+	"context"
+	"maps"
+	"net/http"
+	"testing"
+
+	"github.com/adevinta/ghe-reposec/internal/config"
+)
+
+func TestAlertsCollect(t *testing.T) {
+	codeScanning := func(w http.ResponseWriter, _ *http.Request) {
+		writeJSON(w, []map[string]any{
+			{"rule": map[string]any{"security_severity_level": "critical"}},
+			{"rule": map[string]any{"severity": "warning"}},
+		})
+	}
+	secretScanningDisabled := func(w http.ResponseWriter, _ *http.Request) {
+		writeStatus(w, http.StatusNotFound, "Secret scanning is disabled on this repository.")
+	}
+	dependabotDisabled := func(w http.ResponseWriter, _ *http.Request) {
+		writeStatus(w, http.StatusForbidden, "Dependabot alerts are disabled for this repository.")
+	}
+
+	tests := []struct {
+		name           string
+		secretScanning http.HandlerFunc
+		dependabot     http.HandlerFunc
+		wantErr        bool
+		want           map[string]int
+	}{
+		{
+			name:           "disabled tools",
+			secretScanning: secretScanningDisabled,
+			dependabot:     dependabotDisabled,
+			want:           map[string]int{"code_scanning:critical": 1, "code_scanning:warning": 1},
+		},
+		{
+			name:           "forbidden",
+			secretScanning: secretScanningDisabled,
+			dependabot: func(w http.ResponseWriter, _ *http.Request) {
+				writeStatus(w, http.StatusForbidden, "Resource not accessible by integration")
+			},
+			wantErr: true,
+		},
+		{
+			name: "not found",
+			secretScanning: func(w http.ResponseWriter, _ *http.Request) {
+				writeStatus(w, http.StatusNotFound, "Not Found")
+			},
+			dependabot: dependabotDisabled,
+			wantErr:    true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cli := newTestClient(t, config.GHEConfig{}, map[string]http.HandlerFunc{
+				"GET /repos/org/repo/code-scanning/alerts":   codeScanning,
+				"GET /repos/org/repo/secret-scanning/alerts": tt.secretScanning,
+				"GET /repos/org/repo/dependabot/alerts":      tt.dependabot,
+			})
+
+			repo := Repository{FullName: "org/repo"}
+			err := NewAlerts(cli).Collect(context.Background(), &repo)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !maps.Equal(repo.Alerts, tt.want) {
+				t.Errorf("unexpected alerts: got %v, want %v", repo.Alerts, tt.want)
+			}
+		})
+	}
+}
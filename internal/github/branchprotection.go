@@ -0,0 +1,134 @@
+// Copyright 2025 Adevinta
+
+package github
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	gh "github.com/google/go-github/v67/github"
+)
+
+// Branch protection controls.
+const (
+	ControlRequiredReviews      = "required-reviews"
+	ControlRequiredStatusChecks = "required-status-checks"
+	ControlSignedCommits        = "signed-commits"
+	ControlForcePushBlocked     = "force-push-blocked"
+)
+
+// BranchProtection collects the protections of the default branch of the
+// repositories, from both the branch protection rules and the repository and
+// organization rulesets, and reports them as controls.
+type BranchProtection struct {
+	cli *Client
+}
+
+// NewBranchProtection creates a new branch protection collector.
+func NewBranchProtection(cli *Client) *BranchProtection {
+	return &BranchProtection{cli: cli}
+}
+
+// Name returns the name of the collector.
+func (b *BranchProtection) Name() string {
+	return "branch_protection"
+}
+
+// Collect adds the protections of the default branch of the repository to
+// its Controls field. The branch protection rules can only be read with admin
+// permission, as GitHub Enterprise reports them as not found otherwise.
+// Without it, only the controls enforced by the rulesets are added and
+// [ErrAdminRequired] is returned, as the result is partial.
+func (b *BranchProtection) Collect(ctx context.Context, repo *Repository) error {
+	owner, name, ok := strings.Cut(repo.FullName, "/")
+	if !ok {
+		return fmt.Errorf("invalid repository name: %s", repo.FullName)
+	}
+	if repo.DefaultBranch == "" {
+		return nil
+	}
+	ctx = context.WithValue(ctx, gh.SleepUntilPrimaryRateLimitResetWhenRateLimited, true)
+
+	controls := map[string]bool{}
+
+	if repo.Admin {
+		t := time.Now()
+		protection, _, err := b.cli.client.Repositories.GetBranchProtection(ctx, owner, name, repo.DefaultBranch)
+		b.cli.apiLatency("get_branch_protection", owner, t, err)
+		switch {
+		case err == nil:
+			protectionControls(protection, controls)
+		case errors.Is(err, gh.ErrBranchNotProtected):
+			// The branch is not protected.
+		default:
+			// Other not found errors mean that the branch does not
+			// exist, so its protections are unknown.
+			return fmt.Errorf("failed to get branch protection: %w", err)
+		}
+	}
+
+	t := time.Now()
+	rules, _, err := b.cli.client.Repositories.GetRulesForBranch(ctx, owner, name, repo.DefaultBranch)
+	b.cli.apiLatency("get_rules_for_branch", owner, t, err)
+	if err != nil && !isNotFound(err) {
+		return fmt.Errorf("failed to get branch rules: %w", err)
+	}
+	rulesControls(rules, controls)
+
+	found := []string{}
+	for c, ok := range controls {
+		if ok {
+			found = append(found, c)
+		}
+	}
+	slices.Sort(found)
+	repo.Controls = append(repo.Controls, found...)
+
+	if !repo.Admin {
+		return ErrAdminRequired
+	}
+	return nil
+}
+
+func protectionControls(p *gh.Protection, controls map[string]bool) {
+	if r := p.GetRequiredPullRequestReviews(); r != nil && r.RequiredApprovingReviewCount > 0 {
+		controls[ControlRequiredReviews] = true
+	}
+	if c := p.GetRequiredStatusChecks(); c != nil && (len(c.GetChecks()) > 0 || len(c.GetContexts()) > 0) {
+		controls[ControlRequiredStatusChecks] = true
+	}
+	if p.GetRequiredSignatures().GetEnabled() {
+		controls[ControlSignedCommits] = true
+	}
+	if p.AllowForcePushes == nil || !p.AllowForcePushes.Enabled {
+		controls[ControlForcePushBlocked] = true
+	}
+}
+
+// rulesControls adds the controls enforced by the active rules of a branch,
+// which include the ones of the repository and organization rulesets.
+func rulesControls(rules []*gh.RepositoryRule, controls map[string]bool) {
+	for _, r := range rules {
+		switch r.Type {
+		case "pull_request":
+			var params gh.PullRequestRuleParameters
+			if r.Parameters != nil && json.Unmarshal(*r.Parameters, &params) == nil && params.RequiredApprovingReviewCount > 0 {
+				controls[ControlRequiredReviews] = true
+			}
+		case "required_status_checks":
+			var params gh.RequiredStatusChecksRuleParameters
+			if r.Parameters != nil && json.Unmarshal(*r.Parameters, &params) == nil && len(params.RequiredStatusChecks) > 0 {
+				controls[ControlRequiredStatusChecks] = true
+			}
+		case "required_signatures":
+			controls[ControlSignedCommits] = true
+		case "non_fast_forward":
+			controls[ControlForcePushBlocked] = true
+		}
+	}
+}
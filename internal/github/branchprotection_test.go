@@ -0,0 +1,84 @@
+// Copyright 2025 Adevinta
+
+package github
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"slices"
+	"testing"
+
+	"github.com/adevinta/ghe-reposec/internal/config"
+)
+
+func TestBranchProtectionCollect(t *testing.T) {
+	tests := []struct {
+		name         string
+		admin        bool
+		protection   http.HandlerFunc
+		wantErr      error
+		wantControls []string
+	}{
+		{
+			name:  "protected branch",
+			admin: true,
+			protection: func(w http.ResponseWriter, _ *http.Request) {
+				writeJSON(w, map[string]any{
+					"required_pull_request_reviews": map[string]any{"required_approving_review_count": 1},
+					"allow_force_pushes":            map[string]any{"enabled": true},
+				})
+			},
+			wantControls: []string{ControlRequiredReviews, ControlSignedCommits},
+		},
+		{
+			name:  "unprotected branch",
+			admin: true,
+			protection: func(w http.ResponseWriter, _ *http.Request) {
+				writeStatus(w, http.StatusNotFound, "Branch not protected")
+			},
+			wantControls: []string{ControlSignedCommits},
+		},
+		{
+			name:  "missing branch",
+			admin: true,
+			protection: func(w http.ResponseWriter, _ *http.Request) {
+				writeStatus(w, http.StatusNotFound, "Branch not found")
+			},
+			wantErr: errAny,
+		},
+		{
+			name: "without admin permission",
+			protection: func(w http.ResponseWriter, _ *http.Request) {
+				writeStatus(w, http.StatusNotFound, "Not Found")
+			},
+			wantErr:      ErrAdminRequired,
+			wantControls: []string{ControlSignedCommits},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cli := newTestClient(t, config.GHEConfig{}, map[string]http.HandlerFunc{
+				"GET /repos/org/repo/branches/main/protection": tt.protection,
+				"GET /repos/org/repo/rules/branches/main": func(w http.ResponseWriter, _ *http.Request) {
+					writeJSON(w, []map[string]any{{"type": "required_signatures"}})
+				},
+			})
+
+			repo := Repository{FullName: "org/repo", DefaultBranch: "main", Admin: tt.admin, Controls: []string{"sast"}}
+			err := NewBranchProtection(cli).Collect(context.Background(), &repo)
+			switch {
+			case tt.wantErr == errAny:
+				if err == nil {
+					t.Fatal("expected error")
+				}
+				return
+			case !errors.Is(err, tt.wantErr):
+				t.Fatalf("unexpected error: got %v, want %v", err, tt.wantErr)
+			}
+			if want := append([]string{"sast"}, tt.wantControls...); !slices.Equal(repo.Controls, want) {
+				t.Errorf("unexpected controls: got %q, want %q", repo.Controls, want)
+			}
+		})
+	}
+}
@@ -0,0 +1,78 @@
+// Copyright 2025 Adevinta
+
+package github
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/adevinta/ghe-reposec/internal/tracing"
+)
+
+// Collector collects additional data about a selected repository using the
+// GitHub Enterprise API, and sets it in the repository fields.
+type Collector interface {
+	// Name returns the name of the collector.
+	Name() string
+	// Collect collects the data of the repository.
+	Collect(ctx context.Context, repo *Repository) error
+}
+
+// Collect runs the collectors on the repositories concurrently. Collection
+// errors are logged and reported in the collector.errors metric. The
+// repository fields of the failed collector are left unset, unless it reports
+// a partial result along with the error.
+func (c *Client) Collect(repos []Repository, collectors ...Collector) {
+	if len(collectors) == 0 {
+		return
+	}
+
+	ctx, span := tracing.Tracer().Start(c.ctx, "github.collect")
+	defer span.End()
+
+	c.logger.Debug("collecting repository data", "collectors", len(collectors))
+	sem := make(chan struct{}, c.cfg.Concurrency)
+	var (
+		wg     sync.WaitGroup
+		mu     sync.Mutex
+		errors = map[string]int{}
+	)
+	for _, col := range collectors {
+		errors[col.Name()] = 0
+	}
+	for i := range repos {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			for _, col := range collectors {
+				colCtx, colSpan := tracing.Tracer().Start(ctx, "github.collect."+col.Name(),
+					trace.WithAttributes(attribute.String("repository", repos[i].FullName)),
+				)
+				err := col.Collect(colCtx, &repos[i])
+				if err != nil {
+					c.logger.Error("failed to collect repository data", "collector", col.Name(), "repository", repos[i].FullName, "error", err)
+					colSpan.RecordError(err)
+					colSpan.SetStatus(codes.Error, err.Error())
+					mu.Lock()
+					errors[col.Name()]++
+					mu.Unlock()
+				}
+				colSpan.End()
+			}
+		}()
+	}
+	wg.Wait()
+
+	for k, v := range errors {
+		c.metrics.Gauge("collector.errors", v, []string{fmt.Sprintf("collector:%s", k)})
+	}
+	c.logger.Debug("collecting repository data completed", "errors", errors)
+}
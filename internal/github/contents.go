@@ -0,0 +1,34 @@
+// Copyright 2025 Adevinta
+
+package github
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	gh "github.com/google/go-github/v67/github"
+)
+
+// fileContent returns the content of the file at path in the provided ref of
+// the repository, and whether it exists.
+func (c *Client) fileContent(ctx context.Context, owner, repo, path, ref string) ([]byte, bool, error) {
+	t := time.Now()
+	file, _, _, err := c.client.Repositories.GetContents(ctx, owner, repo, path, &gh.RepositoryContentGetOptions{Ref: ref})
+	c.apiLatency("get_contents", owner, t, err)
+	if isNotFound(err) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to get %s contents: %w", path, err)
+	}
+	if file == nil {
+		// The path is a directory.
+		return nil, false, nil
+	}
+	content, err := file.GetContent()
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to decode %s contents: %w", path, err)
+	}
+	return []byte(content), true, nil
+}
@@ -0,0 +1,225 @@
+// Copyright 2025 Adevinta
+
+package github
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"path"
+	"strings"
+	"sync"
+	"time"
+
+	gh "github.com/google/go-github/v67/github"
+)
+
+// File controls.
+const (
+	ControlSecurityPolicy = "security-policy"
+	ControlCodeowners     = "codeowners"
+	ControlLicense        = "license"
+)
+
+// CODEOWNERS finding rules.
+const (
+	// FindingCodeownersUnknownTeam is reported for every team referenced
+	// in the CODEOWNERS file that does not exist.
+	FindingCodeownersUnknownTeam = "codeowners-unknown-team"
+	// FindingCodeownersUnvalidatedTeam is reported for every team
+	// referenced in the CODEOWNERS file whose existence could not be
+	// checked.
+	FindingCodeownersUnvalidatedTeam = "codeowners-unvalidated-team"
+)
+
+// securityPolicyDirs are the directories where GitHub looks for the
+// SECURITY.md file, in order of precedence.
+var securityPolicyDirs = []string{".github", "", "docs"}
+
+// licenseNames are the prefixes of the license file names.
+var licenseNames = []string{"license", "licence", "copying"}
+
+// Files checks the presence and basic validity of the SECURITY.md,
+// CODEOWNERS and LICENSE files of the repositories.
+type Files struct {
+	cli *Client
+
+	mu    sync.Mutex
+	teams map[string]bool
+}
+
+// NewFiles creates a new files collector.
+func NewFiles(cli *Client) *Files {
+	return &Files{cli: cli, teams: map[string]bool{}}
+}
+
+// Name returns the name of the collector.
+func (f *Files) Name() string {
+	return "files"
+}
+
+// Collect adds a control to the Controls field of the repository for every
+// valid file in its default branch: a non-empty SECURITY.md, a CODEOWNERS
+// with owners and referencing only existing teams, and a non-empty LICENSE.
+// Unknown CODEOWNERS teams are added to its Findings field.
+func (f *Files) Collect(ctx context.Context, repo *Repository) error {
+	owner, name, ok := strings.Cut(repo.FullName, "/")
+	if !ok {
+		return fmt.Errorf("invalid repository name: %s", repo.FullName)
+	}
+	ctx = context.WithValue(ctx, gh.SleepUntilPrimaryRateLimitResetWhenRateLimited, true)
+
+	dirs := map[string][]*gh.RepositoryContent{}
+	for _, dir := range securityPolicyDirs {
+		entries, err := f.listDir(ctx, owner, name, dir, repo.DefaultBranch)
+		if err != nil {
+			return err
+		}
+		dirs[dir] = entries
+	}
+
+	for _, dir := range securityPolicyDirs {
+		entry := findFile(dirs[dir], func(n string) bool { return strings.EqualFold(n, "SECURITY.md") })
+		if entry == nil {
+			continue
+		}
+		content, ok, err := f.cli.fileContent(ctx, owner, name, entry.GetPath(), repo.DefaultBranch)
+		if err != nil {
+			return err
+		}
+		if ok && len(bytes.TrimSpace(content)) > 0 {
+			repo.Controls = append(repo.Controls, ControlSecurityPolicy)
+			break
+		}
+	}
+
+	license := findFile(dirs[""], func(n string) bool {
+		n = strings.ToLower(n)
+		for _, l := range licenseNames {
+			if n == l || strings.HasPrefix(n, l+".") || strings.HasPrefix(n, l+"-") {
+				return true
+			}
+		}
+		return false
+	})
+	if license != nil && license.GetSize() > 0 {
+		repo.Controls = append(repo.Controls, ControlLicense)
+	}
+
+	for _, p := range codeownersPaths {
+		dir := path.Dir(p)
+		if dir == "." {
+			dir = ""
+		}
+		if findFile(dirs[dir], func(n string) bool { return n == path.Base(p) }) == nil {
+			continue
+		}
+		content, ok, err := f.cli.fileContent(ctx, owner, name, p, repo.DefaultBranch)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			continue
+		}
+		valid, err := f.validCodeowners(ctx, p, content, repo)
+		if err != nil {
+			return err
+		}
+		if valid {
+			repo.Controls = append(repo.Controls, ControlCodeowners)
+		}
+		break
+	}
+
+	return nil
+}
+
+// validCodeowners returns whether the CODEOWNERS file has any rule with
+// owners and all the teams it references exist. Unknown teams, and teams
+// whose existence could not be checked, are reported as findings of the
+// repository, and the file is not considered valid.
+func (f *Files) validCodeowners(ctx context.Context, file string, content []byte, repo *Repository) (bool, error) {
+	rules, err := codeownersRules(bytes.NewReader(content))
+	if err != nil {
+		return false, err
+	}
+
+	hasOwners, unknownTeams := false, false
+	checked := map[string]bool{}
+	for _, rule := range rules {
+		for _, o := range rule[1:] {
+			hasOwners = true
+			team := strings.TrimPrefix(o, "@")
+			org, slug, ok := strings.Cut(team, "/")
+			if !ok || team == o || checked[team] {
+				continue
+			}
+			checked[team] = true
+			exists, err := f.teamExists(ctx, org, slug)
+			if err != nil {
+				f.cli.logger.Warn("failed to validate CODEOWNERS team", "repository", repo.FullName, "team", team, "error", err)
+				repo.Findings = append(repo.Findings, FindingCodeownersUnvalidatedTeam+":"+file+":"+team)
+				unknownTeams = true
+				continue
+			}
+			if !exists {
+				repo.Findings = append(repo.Findings, FindingCodeownersUnknownTeam+":"+file+":"+team)
+				unknownTeams = true
+			}
+		}
+	}
+	return hasOwners && !unknownTeams, nil
+}
+
+// teamExists returns whether the team exists. Results are cached, errors are
+// not.
+func (f *Files) teamExists(ctx context.Context, org, slug string) (bool, error) {
+	key := strings.ToLower(org + "/" + slug)
+	f.mu.Lock()
+	exists, ok := f.teams[key]
+	f.mu.Unlock()
+	if ok {
+		return exists, nil
+	}
+
+	t := time.Now()
+	_, _, err := f.cli.client.Teams.GetTeamBySlug(ctx, org, slug)
+	f.cli.apiLatency("get_team", org, t, err)
+	switch {
+	case err == nil:
+		exists = true
+	case isNotFound(err):
+		exists = false
+	default:
+		return false, fmt.Errorf("failed to get team %s/%s: %w", org, slug, err)
+	}
+
+	f.mu.Lock()
+	f.teams[key] = exists
+	f.mu.Unlock()
+	return exists, nil
+}
+
+// listDir returns the entries of a directory of the repository or nil if it
+// does not exist.
+func (f *Files) listDir(ctx context.Context, owner, repo, dir, ref string) ([]*gh.RepositoryContent, error) {
+	t := time.Now()
+	_, entries, _, err := f.cli.client.Repositories.GetContents(ctx, owner, repo, dir, &gh.RepositoryContentGetOptions{Ref: ref})
+	f.cli.apiLatency("get_contents", owner, t, err)
+	if isNotFound(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to list %q contents: %w", dir, err)
+	}
+	return entries, nil
+}
+
+func findFile(entries []*gh.RepositoryContent, match func(name string) bool) *gh.RepositoryContent {
+	for _, e := range entries {
+		if e.GetType() == "file" && match(e.GetName()) {
+			return e
+		}
+	}
+	return nil
+}
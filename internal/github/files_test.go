@@ -0,0 +1,93 @@
+// Copyright 2025 Adevinta
+
+package github
+
+import (
+	"context"
+	"encoding/base64"
+	"net/http"
+	"slices"
+	"testing"
+
+	"github.com/adevinta/ghe-reposec/internal/config"
+)
+
+func TestFilesCollect(t *testing.T) {
+	file := func(name, content string) http.HandlerFunc {
+		return func(w http.ResponseWriter, _ *http.Request) {
+			writeJSON(w, map[string]any{
+				"type":     "file",
+				"name":     name,
+				"encoding": "base64",
+				"content":  base64.StdEncoding.EncodeToString([]byte(content)),
+			})
+		}
+	}
+	dir := func(names ...string) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			prefix := r.PathValue("dir")
+			if prefix != "" {
+				prefix += "/"
+			}
+			entries := []map[string]any{}
+			for _, n := range names {
+				entries = append(entries, map[string]any{"type": "file", "name": n, "path": prefix + n, "size": 1})
+			}
+			writeJSON(w, entries)
+		}
+	}
+
+	tests := []struct {
+		name         string
+		team         http.HandlerFunc
+		wantControls []string
+		wantFindings []string
+	}{
+		{
+			name: "existing team",
+			team: func(w http.ResponseWriter, _ *http.Request) {
+				writeJSON(w, map[string]any{"slug": "security"})
+			},
+			wantControls: []string{ControlSecurityPolicy, ControlLicense, ControlCodeowners},
+		},
+		{
+			name: "unknown team",
+			team: func(w http.ResponseWriter, _ *http.Request) {
+				writeStatus(w, http.StatusNotFound, "Not Found")
+			},
+			wantControls: []string{ControlSecurityPolicy, ControlLicense},
+			wantFindings: []string{"codeowners-unknown-team:.github/CODEOWNERS:org/security"},
+		},
+		{
+			name: "forbidden team",
+			team: func(w http.ResponseWriter, _ *http.Request) {
+				writeStatus(w, http.StatusForbidden, "Resource not accessible by integration")
+			},
+			wantControls: []string{ControlSecurityPolicy, ControlLicense},
+			wantFindings: []string{"codeowners-unvalidated-team:.github/CODEOWNERS:org/security"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cli := newTestClient(t, config.GHEConfig{}, map[string]http.HandlerFunc{
+				"GET /repos/org/repo/contents/{$}":                 dir("LICENSE", "SECURITY.md"),
+				"GET /repos/org/repo/contents/{dir}":               dir("SECURITY.md", "CODEOWNERS"),
+				"GET /repos/org/repo/contents/.github/SECURITY.md": file("SECURITY.md", "\n"),
+				"GET /repos/org/repo/contents/SECURITY.md":         file("SECURITY.md", "Report issues to security@example.com"),
+				"GET /repos/org/repo/contents/.github/CODEOWNERS":  file("CODEOWNERS", "* @org/security\n"),
+				"GET /orgs/org/teams/security":                     tt.team,
+			})
+
+			repo := Repository{FullName: "org/repo", DefaultBranch: "main"}
+			if err := NewFiles(cli).Collect(context.Background(), &repo); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !slices.Equal(repo.Controls, tt.wantControls) {
+				t.Errorf("unexpected controls: got %q, want %q", repo.Controls, tt.wantControls)
+			}
+			if !slices.Equal(repo.Findings, tt.wantFindings) {
+				t.Errorf("unexpected findings: got %q, want %q", repo.Findings, tt.wantFindings)
+			}
+		})
+	}
+}
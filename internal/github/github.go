@@ -12,9 +12,13 @@ import (
 	"time"
 
 	gh "github.com/google/go-github/v67/github"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
 
 	"github.com/adevinta/ghe-reposec/internal/config"
 	"github.com/adevinta/ghe-reposec/internal/metrics"
+	"github.com/adevinta/ghe-reposec/internal/tracing"
 )
 
 var (
@@ -22,11 +26,66 @@ var (
 	ErrTokenRequired = fmt.Errorf("GitHub Enterprise token is required")
 	// ErrAPIBaseURLRequired is returned when a GitHub Enterprise API base URL is not provided.
 	ErrAPIBaseURLRequired = fmt.Errorf("GitHub Enterprise API base URL is required")
+	// ErrAdminRequired is returned when a collector requires admin permission on
+	// the repository and the GitHub Enterprise token user does not have it.
+	ErrAdminRequired = fmt.Errorf("admin permission on the repository is required")
 )
 
+// Repository represents a selected GitHub Enterprise repository.
+type Repository struct {
+	Organization  string
+	Name          string
+	FullName      string
+	CloneURL      string
+	DefaultBranch string
+	Language      string
+	Visibility    string
+	Topics        []string
+	// Admin reports whether the GitHub Enterprise token user has admin
+	// permission on the repository.
+	Admin  bool
+	Owners []string
+	// Controls are the security controls found by the collectors.
+	Controls []string
+	// Alerts are the number of open security alerts keyed by
+	// "tool:severity".
+	Alerts map[string]int
+	// Findings are the risky patterns found by the collectors, formatted as
+	// "rule:subject:detail".
+	Findings []string
+	// OutsideCollaborators are the outside collaborators with write access.
+	OutsideCollaborators []Collaborator
+	// DeployKeys are the deploy keys with write access.
+	DeployKeys []DeployKey
+	// AccessAudited reports whether the outside collaborators and the deploy
+	// keys were collected.
+	AccessAudited bool
+
+	// securityAndAnalysis is the security and analysis status reported by
+	// the repository listing.
+	securityAndAnalysis *gh.SecurityAndAnalysis
+}
+
+func newRepository(org string, repo *gh.Repository) Repository {
+	return Repository{
+		Organization:  org,
+		Name:          repo.GetName(),
+		FullName:      repo.GetFullName(),
+		CloneURL:      repo.GetCloneURL(),
+		DefaultBranch: repo.GetDefaultBranch(),
+		Language:      repo.GetLanguage(),
+		Visibility:    repo.GetVisibility(),
+		Topics:        repo.Topics,
+		Admin:         repo.GetPermissions()["admin"],
+
+		securityAndAnalysis: repo.GetSecurityAndAnalysis(),
+	}
+}
+
 // Client is a GitHub client wrapper.
 type Client struct {
 	cfg     config.GHEConfig
+	login   string
 	client  *gh.Client
 	logger  *slog.Logger
 	metrics *metrics.Client
@@ -62,6 +121,7 @@ func NewClient(ctx context.Context, logger *slog.Logger, m *metrics.Client, cfg
 
 	return &Client{
 		cfg:     cfg,
+		login:   user.GetLogin(),
 		logger:  logger,
 		client:  client,
 		metrics: m,
@@ -71,17 +131,28 @@ func NewClient(ctx context.Context, logger *slog.Logger, m *metrics.Client, cfg
 
 // Organizations returns the list of all GitHub Enterprise organizations.
 func (c *Client) Organizations() ([]string, error) {
+	ctx, span := tracing.Tracer().Start(c.ctx, "github.organizations")
+	defer span.End()
+
 	allOrgs := []string{}
 	c.logger.Debug("listing organizations")
 	orgsOpts := &gh.OrganizationsListOptions{
 		ListOptions: gh.ListOptions{PerPage: 100},
 	}
-	for {
+	for page := 1; ; page++ {
+		pageCtx, pageSpan := tracing.Tracer().Start(ctx, "github.api.list_organizations",
+			trace.WithAttributes(attribute.Int("github.page", page)),
+		)
+		t := time.Now()
 		orgs, resp, err := c.client.Organizations.ListAll(
-			context.WithValue(context.Background(), gh.SleepUntilPrimaryRateLimitResetWhenRateLimited, true),
+			context.WithValue(pageCtx, gh.SleepUntilPrimaryRateLimitResetWhenRateLimited, true),
 			orgsOpts,
 		)
+		c.apiLatency("list_organizations", "", t, err)
+		endPageSpan(pageSpan, resp, err)
 		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
 			return []string{}, fmt.Errorf("failed to list organizations: %w", err)
 		}
 		for _, org := range orgs {
@@ -93,13 +164,17 @@ func (c *Client) Organizations() ([]string, error) {
 		orgsOpts.Since = *orgs[len(orgs)-1].ID
 	}
 	c.logger.Debug("listing organizations completed", "organizations", len(allOrgs))
+	span.SetAttributes(attribute.Int("github.organizations", len(allOrgs)))
 
 	return allOrgs, nil
 }
 
 // Repositories returns the list of selected repositories from the targetOrg or
 // all GitHub Enterprise organizations if targetOrg is not provided.
-func (c *Client) Repositories(targetOrg string) ([]string, error) {
+func (c *Client) Repositories(targetOrg string) ([]Repository, error) {
+	ctx, span := tracing.Tracer().Start(c.ctx, "github.repositories")
+	defer span.End()
+
 	var orgs []string
 	var err error
 
@@ -108,40 +183,48 @@ func (c *Client) Repositories(targetOrg string) ([]string, error) {
 	} else {
 		orgs, err = c.Organizations()
 		if err != nil {
-			return []string{}, fmt.Errorf("failed to list organizations: %w", err)
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+			return []Repository{}, fmt.Errorf("failed to list organizations: %w", err)
 		}
 	}
 	c.metrics.Gauge("organizations", len(orgs), []string{})
 
 	c.logger.Debug("listing repositories")
 	sem := make(chan struct{}, c.cfg.Concurrency)
-	reposResultChan := make(chan []string)
+	reposResultChan := make(chan []Repository)
 
 	var wg sync.WaitGroup
 	for _, org := range orgs {
 		wg.Add(1)
-		go orgRepositories(c, org, &wg, sem, reposResultChan)
+		go orgRepositories(ctx, c, org, &wg, sem, reposResultChan)
 	}
 	go func() {
 		wg.Wait()
 		close(reposResultChan)
 	}()
 
-	selectedRepos := []string{}
+	selectedRepos := []Repository{}
 	for repos := range reposResultChan {
 		selectedRepos = append(selectedRepos, repos...)
 	}
 	c.logger.Debug("listing repositories completed", "repositories", len(selectedRepos))
+	span.SetAttributes(attribute.Int("github.repositories", len(selectedRepos)))
 
 	return selectedRepos, nil
 }
 
-func orgRepositories(c *Client, org string, wg *sync.WaitGroup, sem chan struct{}, resultChan chan<- []string) {
+func orgRepositories(ctx context.Context, c *Client, org string, wg *sync.WaitGroup, sem chan struct{}, resultChan chan<- []Repository) {
 	defer wg.Done()
 
 	sem <- struct{}{}
 	defer func() { <-sem }()
 
+	ctx, span := tracing.Tracer().Start(ctx, "github.org_repositories",
+		trace.WithAttributes(attribute.String("github.organization", org)),
+	)
+	defer span.End()
+
 	c.logger.Debug("obtaining repositories for organization", "organization", org)
 
 	repoMetrics := map[string]int{
@@ -154,16 +237,26 @@ func orgRepositories(c *Client, org string, wg *sync.WaitGroup, sem chan struct{
 		"inactive": 0,
 		"selected": 0,
 	}
-	allRepos := []string{}
+	allRepos := []Repository{}
 	listOpts := &gh.RepositoryListByOrgOptions{ListOptions: gh.ListOptions{PerPage: 100}}
 	for {
+		pageCtx, pageSpan := tracing.Tracer().Start(ctx, "github.api.list_repositories",
+			trace.WithAttributes(
+				attribute.String("github.organization", org),
+				attribute.Int("github.page", max(listOpts.Page, 1)),
+			),
+		)
+		t := time.Now()
 		repos, resp, err := c.client.Repositories.ListByOrg(
-			context.WithValue(c.ctx, gh.SleepUntilPrimaryRateLimitResetWhenRateLimited, true),
+			context.WithValue(pageCtx, gh.SleepUntilPrimaryRateLimitResetWhenRateLimited, true),
 			org,
 			listOpts,
 		)
+		c.apiLatency("list_repositories", org, t, err)
+		endPageSpan(pageSpan, resp, err)
 		if err != nil {
 			c.logger.Error("failed to list repositories for organization", "organization", org, "error", err)
+			span.RecordError(err)
 		}
 		if err != nil && resp.NextPage == 0 {
 			break
@@ -220,7 +313,7 @@ func orgRepositories(c *Client, org string, wg *sync.WaitGroup, sem chan struct{
 					continue
 				}
 			}
-			allRepos = append(allRepos, *repo.CloneURL)
+			allRepos = append(allRepos, newRepository(org, repo))
 			repoMetrics["selected"]++
 		}
 		if resp.NextPage == 0 {
@@ -230,6 +323,7 @@ func orgRepositories(c *Client, org string, wg *sync.WaitGroup, sem chan struct{
 	}
 
 	c.logger.Debug("organization repository listing completed", "organization", org, "repositories", len(allRepos))
+	span.SetAttributes(attribute.Int("github.repositories", len(allRepos)))
 	for k, v := range repoMetrics {
 		c.metrics.Gauge("repositories", v, []string{
 			fmt.Sprintf("status:%s", k),
@@ -239,3 +333,33 @@ func orgRepositories(c *Client, org string, wg *sync.WaitGroup, sem chan struct{
 
 	resultChan <- allRepos
 }
+
+// apiLatency reports the latency of a GitHub API request started at t. Note
+// that it includes the time waiting for the rate limit to be reset. The
+// organization tag is always reported, empty if the request does not target
+// an organization, so every series shares the same set of labels.
+func (c *Client) apiLatency(endpoint, org string, t time.Time, err error) {
+	status := "success"
+	if err != nil {
+		status = "error"
+	}
+	tags := []string{
+		fmt.Sprintf("endpoint:%s", endpoint),
+		fmt.Sprintf("status:%s", status),
+		fmt.Sprintf("organization:%s", org),
+	}
+	c.metrics.Timing("github.api.latency", time.Since(t), tags)
+}
+
+// endPageSpan records the result of a GitHub API page request and ends its
+// span.
+func endPageSpan(span trace.Span, resp *gh.Response, err error) {
+	if resp != nil && resp.Response != nil {
+		span.SetAttributes(attribute.Int("http.response.status_code", resp.StatusCode))
+	}
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
@@ -0,0 +1,117 @@
+// Copyright 2025 Adevinta
+
+package github
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+
+	"github.com/adevinta/ghe-reposec/internal/config"
+	"github.com/adevinta/ghe-reposec/internal/metrics"
+)
+
+// newTestClient returns a client for a GitHub Enterprise stand-in serving
+// the provided handlers, keyed by "METHOD /path" patterns relative to the
+// API path.
+func newTestClient(t *testing.T, cfg config.GHEConfig, handlers map[string]http.HandlerFunc) *Client {
+	t.Helper()
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/v3/user", func(w http.ResponseWriter, _ *http.Request) {
+		writeJSON(w, map[string]any{"login": "reposec"})
+	})
+	for pattern, h := range handlers {
+		method, path, _ := strings.Cut(pattern, " ")
+		mux.HandleFunc(method+" /api/v3"+path, h)
+	}
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	m, err := metrics.NewClient(context.Background(), logger, config.MetricsConfig{})
+	if err != nil {
+		t.Fatalf("unexpected error creating metrics client: %v", err)
+	}
+	cfg.Token = "token"
+	cfg.BaseURL = srv.URL
+	cli, err := NewClient(context.Background(), logger, m, cfg)
+	if err != nil {
+		t.Fatalf("unexpected error creating client: %v", err)
+	}
+	return cli
+}
+
+func writeJSON(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(v)
+}
+
+func writeStatus(w http.ResponseWriter, status int, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(map[string]string{"message": message})
+}
+
+func TestRepositoriesTracing(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	prev := otel.GetTracerProvider()
+	otel.SetTracerProvider(tp)
+	t.Cleanup(func() { otel.SetTracerProvider(prev) })
+
+	cli := newTestClient(t, config.GHEConfig{RepositorySizeLimit: 1000}, map[string]http.HandlerFunc{
+		"GET /orgs/org/repos": func(w http.ResponseWriter, _ *http.Request) {
+			writeJSON(w, []map[string]any{
+				{"name": "repo", "full_name": "org/repo", "size": 10},
+				{"name": "big", "full_name": "org/big", "size": 2000},
+			})
+		},
+	})
+
+	repos, err := cli.Repositories("org")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(repos) != 1 || repos[0].FullName != "org/repo" {
+		t.Fatalf("unexpected repositories: %+v", repos)
+	}
+
+	spans := map[string]sdktrace.ReadOnlySpan{}
+	for _, s := range recorder.Ended() {
+		spans[s.Name()] = s
+	}
+	for _, name := range []string{"github.repositories", "github.org_repositories", "github.api.list_repositories"} {
+		if _, ok := spans[name]; !ok {
+			t.Errorf("missing span %q", name)
+		}
+	}
+	root, page := spans["github.repositories"], spans["github.api.list_repositories"]
+	if root == nil || page == nil {
+		t.FailNow()
+	}
+	if page.SpanContext().TraceID() != root.SpanContext().TraceID() {
+		t.Errorf("page span not in the discovery trace")
+	}
+	i := slices.IndexFunc(page.Attributes(), func(kv attribute.KeyValue) bool {
+		return string(kv.Key) == "http.response.status_code"
+	})
+	if i < 0 || page.Attributes()[i].Value.AsInt64() != http.StatusOK {
+		t.Errorf("unexpected page span attributes: %v", page.Attributes())
+	}
+}
+
+// errAny is used by the tests expecting any error.
+var errAny = errors.New("any error")
@@ -0,0 +1,139 @@
+// Copyright 2025 Adevinta
+
+package github
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	gh "github.com/google/go-github/v67/github"
+)
+
+// Issue represents an issue managed by ghe-reposec. The issue is identified
+// by its label and by the marker contained in its body.
+type Issue struct {
+	Title  string
+	Body   string
+	Label  string
+	Marker string
+}
+
+// IssueChange is the change made to an issue by [Client.EnsureIssue].
+type IssueChange int
+
+// Issue changes.
+const (
+	IssueUnchanged IssueChange = iota
+	IssueUpdated
+	IssueCreated
+)
+
+// EnsureIssue opens the issue in the repository or, if an open issue with the
+// same label and marker already exists, updates its title and body. It
+// returns the issue number and the change made to it.
+func (c *Client) EnsureIssue(owner, repo string, issue Issue) (int, IssueChange, error) {
+	ctx := context.WithValue(c.ctx, gh.SleepUntilPrimaryRateLimitResetWhenRateLimited, true)
+	body := issue.Marker + "\n" + issue.Body
+
+	existing, err := c.findIssue(ctx, owner, repo, issue)
+	if err != nil {
+		return 0, IssueUnchanged, err
+	}
+	if existing != nil {
+		if existing.GetTitle() == issue.Title && existing.GetBody() == body {
+			return existing.GetNumber(), IssueUnchanged, nil
+		}
+		t := time.Now()
+		_, _, err := c.client.Issues.Edit(ctx, owner, repo, existing.GetNumber(), &gh.IssueRequest{
+			Title: gh.String(issue.Title),
+			Body:  gh.String(body),
+		})
+		c.apiLatency("edit_issue", owner, t, err)
+		if err != nil {
+			return 0, IssueUnchanged, fmt.Errorf("failed to update issue %d: %w", existing.GetNumber(), err)
+		}
+		return existing.GetNumber(), IssueUpdated, nil
+	}
+
+	t := time.Now()
+	created, _, err := c.client.Issues.Create(ctx, owner, repo, &gh.IssueRequest{
+		Title:  gh.String(issue.Title),
+		Body:   gh.String(body),
+		Labels: &[]string{issue.Label},
+	})
+	c.apiLatency("create_issue", owner, t, err)
+	if err != nil {
+		return 0, IssueUnchanged, fmt.Errorf("failed to create issue: %w", err)
+	}
+	return created.GetNumber(), IssueCreated, nil
+}
+
+// OpenIssue returns the number of the open issue with the same label and
+// marker in the repository, and whether it exists.
+func (c *Client) OpenIssue(owner, repo string, issue Issue) (int, bool, error) {
+	ctx := context.WithValue(c.ctx, gh.SleepUntilPrimaryRateLimitResetWhenRateLimited, true)
+
+	existing, err := c.findIssue(ctx, owner, repo, issue)
+	if err != nil {
+		return 0, false, err
+	}
+	if existing == nil {
+		return 0, false, nil
+	}
+	return existing.GetNumber(), true, nil
+}
+
+// CloseIssue closes the issue with the provided number leaving the provided
+// comment.
+func (c *Client) CloseIssue(owner, repo string, number int, comment string) error {
+	ctx := context.WithValue(c.ctx, gh.SleepUntilPrimaryRateLimitResetWhenRateLimited, true)
+
+	if comment != "" {
+		t := time.Now()
+		_, _, err := c.client.Issues.CreateComment(ctx, owner, repo, number, &gh.IssueComment{Body: gh.String(comment)})
+		c.apiLatency("create_issue_comment", owner, t, err)
+		if err != nil {
+			return fmt.Errorf("failed to comment issue %d: %w", number, err)
+		}
+	}
+	t := time.Now()
+	_, _, err := c.client.Issues.Edit(ctx, owner, repo, number, &gh.IssueRequest{
+		State:       gh.String("closed"),
+		StateReason: gh.String("completed"),
+	})
+	c.apiLatency("edit_issue", owner, t, err)
+	if err != nil {
+		return fmt.Errorf("failed to close issue %d: %w", number, err)
+	}
+	return nil
+}
+
+func (c *Client) findIssue(ctx context.Context, owner, repo string, issue Issue) (*gh.Issue, error) {
+	opts := &gh.IssueListByRepoOptions{
+		State:       "open",
+		Labels:      []string{issue.Label},
+		ListOptions: gh.ListOptions{PerPage: 100},
+	}
+	for {
+		t := time.Now()
+		issues, resp, err := c.client.Issues.ListByRepo(ctx, owner, repo, opts)
+		c.apiLatency("list_issues", owner, t, err)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list issues: %w", err)
+		}
+		for _, i := range issues {
+			if i.IsPullRequest() {
+				continue
+			}
+			if strings.Contains(i.GetBody(), issue.Marker) {
+				return i, nil
+			}
+		}
+		if resp.NextPage == 0 {
+			return nil, nil
+		}
+		opts.Page = resp.NextPage
+	}
+}
@@ -0,0 +1,114 @@
+// Copyright 2025 Adevinta
+
+package github
+
+import (
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/adevinta/ghe-reposec/internal/config"
+)
+
+func TestOpenIssue(t *testing.T) {
+	issue := Issue{Label: "reposec", Marker: "<!-- marker -->"}
+	tests := []struct {
+		name     string
+		existing []map[string]any
+		wantOK   bool
+	}{
+		{
+			name: "open issue",
+			existing: []map[string]any{
+				{"number": 1, "body": "unrelated"},
+				{"number": 2, "body": "<!-- marker -->", "pull_request": map[string]any{"url": "https://ghe.example.com/pulls/2"}},
+				{"number": 3, "body": "<!-- marker -->\nbody"},
+			},
+			wantOK: true,
+		},
+		{
+			name:     "no issue",
+			existing: []map[string]any{{"number": 1, "body": "unrelated"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var query url.Values
+			cli := newTestClient(t, config.GHEConfig{}, map[string]http.HandlerFunc{
+				"GET /repos/org/repo/issues": func(w http.ResponseWriter, r *http.Request) {
+					query = r.URL.Query()
+					writeJSON(w, tt.existing)
+				},
+			})
+
+			n, ok, err := cli.OpenIssue("org", "repo", issue)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if ok != tt.wantOK || (ok && n != 3) {
+				t.Errorf("unexpected open issue: got %d, %v", n, ok)
+			}
+			if query.Get("state") != "open" || query.Get("labels") != "reposec" {
+				t.Errorf("unexpected query: %v", query)
+			}
+		})
+	}
+}
+
+func TestEnsureIssue(t *testing.T) {
+	issue := Issue{Title: "title", Body: "body", Label: "reposec", Marker: "<!-- marker -->"}
+	tests := []struct {
+		name       string
+		existing   []map[string]any
+		wantChange IssueChange
+		wantEdits  int
+		wantCreate int
+	}{
+		{
+			name:       "created",
+			existing:   []map[string]any{},
+			wantChange: IssueCreated,
+			wantCreate: 1,
+		},
+		{
+			name:       "unchanged",
+			existing:   []map[string]any{{"number": 7, "title": "title", "body": "<!-- marker -->\nbody"}},
+			wantChange: IssueUnchanged,
+		},
+		{
+			name:       "updated",
+			existing:   []map[string]any{{"number": 7, "title": "title", "body": "<!-- marker -->\nold body"}},
+			wantChange: IssueUpdated,
+			wantEdits:  1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var edits, creates int
+			cli := newTestClient(t, config.GHEConfig{}, map[string]http.HandlerFunc{
+				"GET /repos/org/repo/issues": func(w http.ResponseWriter, _ *http.Request) {
+					writeJSON(w, tt.existing)
+				},
+				"PATCH /repos/org/repo/issues/7": func(w http.ResponseWriter, _ *http.Request) {
+					edits++
+					writeJSON(w, map[string]any{"number": 7})
+				},
+				"POST /repos/org/repo/issues": func(w http.ResponseWriter, _ *http.Request) {
+					creates++
+					writeJSON(w, map[string]any{"number": 8})
+				},
+			})
+
+			_, change, err := cli.EnsureIssue("org", "repo", issue)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if change != tt.wantChange {
+				t.Errorf("unexpected change: got %v, want %v", change, tt.wantChange)
+			}
+			if edits != tt.wantEdits || creates != tt.wantCreate {
+				t.Errorf("unexpected requests: got %d edits and %d creates, want %d and %d", edits, creates, tt.wantEdits, tt.wantCreate)
+			}
+		})
+	}
+}
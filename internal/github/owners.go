@@ -0,0 +1,216 @@
+// Copyright 2025 Adevinta
+
+package github
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"time"
+
+	gh "github.com/google/go-github/v67/github"
+
+	"github.com/adevinta/ghe-reposec/internal/config"
+)
+
+// Owner sources.
+const (
+	OwnerSourceCodeowners = "codeowners"
+	OwnerSourceTeams      = "teams"
+	OwnerSourceProperty   = "property"
+)
+
+// codeownersPaths are the locations where GitHub looks for the CODEOWNERS
+// file, in order of precedence.
+var codeownersPaths = []string{".github/CODEOWNERS", "CODEOWNERS", "docs/CODEOWNERS"}
+
+// OwnerResolver resolves the owners of the repositories.
+type OwnerResolver struct {
+	cfg config.OwnersConfig
+	cli *Client
+}
+
+// NewOwnerResolver creates a new owner resolver.
+func NewOwnerResolver(cli *Client, cfg config.OwnersConfig) (*OwnerResolver, error) {
+	for _, src := range cfg.Sources {
+		switch src {
+		case OwnerSourceCodeowners, OwnerSourceTeams:
+		case OwnerSourceProperty:
+			if cfg.Property == "" {
+				return nil, fmt.Errorf("owner custom property name is required")
+			}
+		default:
+			return nil, fmt.Errorf("unsupported owner source: %s", src)
+		}
+	}
+	return &OwnerResolver{cfg: cfg, cli: cli}, nil
+}
+
+// resolve returns the owners of the repository from the first configured
+// source reporting any. Teams are returned as "organization/team" and users
+// as their login.
+func (r *OwnerResolver) resolve(ctx context.Context, repo Repository) ([]string, error) {
+	owner, name, ok := strings.Cut(repo.FullName, "/")
+	if !ok {
+		return nil, fmt.Errorf("invalid repository name: %s", repo.FullName)
+	}
+	ctx = context.WithValue(ctx, gh.SleepUntilPrimaryRateLimitResetWhenRateLimited, true)
+
+	for _, src := range r.cfg.Sources {
+		var (
+			owners []string
+			err    error
+		)
+		switch src {
+		case OwnerSourceCodeowners:
+			owners, err = r.codeowners(ctx, owner, name, repo.DefaultBranch)
+		case OwnerSourceTeams:
+			owners, err = r.teams(ctx, owner, name)
+		case OwnerSourceProperty:
+			owners, err = r.property(ctx, owner, name)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve owners from %s: %w", src, err)
+		}
+		if len(owners) > 0 {
+			return owners, nil
+		}
+	}
+	return []string{}, nil
+}
+
+// Name returns the name of the collector.
+func (r *OwnerResolver) Name() string {
+	return "owners"
+}
+
+// Collect resolves the owners of the repository and sets them in its Owners
+// field.
+func (r *OwnerResolver) Collect(ctx context.Context, repo *Repository) error {
+	owners, err := r.resolve(ctx, *repo)
+	if err != nil {
+		return err
+	}
+	repo.Owners = owners
+	return nil
+}
+
+// codeowners returns the owners of the last CODEOWNERS rule matching all the
+// files of the repository.
+func (r *OwnerResolver) codeowners(ctx context.Context, owner, repo, ref string) ([]string, error) {
+	for _, p := range codeownersPaths {
+		content, ok, err := r.cli.fileContent(ctx, owner, repo, p, ref)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			return parseCodeowners(bytes.NewReader(content))
+		}
+	}
+	return nil, nil
+}
+
+// parseCodeowners returns the owners of the last CODEOWNERS rule matching all
+// the files of the repository.
+func parseCodeowners(r io.Reader) ([]string, error) {
+	rules, err := codeownersRules(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var owners []string
+	for _, rule := range rules {
+		switch rule[0] {
+		case "*", "/*", "/", "**":
+			owners = []string{}
+			for _, o := range rule[1:] {
+				owners = append(owners, strings.TrimPrefix(o, "@"))
+			}
+		}
+	}
+	return owners, nil
+}
+
+// codeownersRules returns the rules of a CODEOWNERS file, as the pattern
+// followed by its owners.
+func codeownersRules(r io.Reader) ([][]string, error) {
+	var rules [][]string
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line, _, _ := strings.Cut(scanner.Text(), "#")
+		if fields := strings.Fields(line); len(fields) > 0 {
+			rules = append(rules, fields)
+		}
+	}
+	return rules, scanner.Err()
+}
+
+// teams returns the teams with admin or maintain permission on the
+// repository.
+func (r *OwnerResolver) teams(ctx context.Context, owner, repo string) ([]string, error) {
+	owners := []string{}
+	opts := &gh.ListOptions{PerPage: 100}
+	for {
+		t := time.Now()
+		teams, resp, err := r.cli.client.Repositories.ListTeams(ctx, owner, repo, opts)
+		r.cli.apiLatency("list_repository_teams", owner, t, err)
+		if err != nil {
+			return nil, err
+		}
+		for _, team := range teams {
+			if p := team.GetPermission(); p == "admin" || p == "maintain" {
+				owners = append(owners, owner+"/"+team.GetSlug())
+			}
+		}
+		if resp.NextPage == 0 {
+			break
+		}
+		opts.Page = resp.NextPage
+	}
+	slices.Sort(owners)
+	return owners, nil
+}
+
+// property returns the value of the configured custom property. Values of
+// unsupported types are logged and ignored.
+func (r *OwnerResolver) property(ctx context.Context, owner, repo string) ([]string, error) {
+	// The values are decoded here, as go-github fails to decode all of them
+	// if any has an unsupported type.
+	req, err := r.cli.client.NewRequest("GET", fmt.Sprintf("repos/%s/%s/properties/values", owner, repo), nil)
+	if err != nil {
+		return nil, err
+	}
+	var values []struct {
+		PropertyName string          `json:"property_name"`
+		Value        json.RawMessage `json:"value"`
+	}
+	t := time.Now()
+	_, err = r.cli.client.Do(ctx, req, &values)
+	r.cli.apiLatency("get_custom_properties", owner, t, err)
+	if err != nil {
+		return nil, err
+	}
+	for _, v := range values {
+		if v.PropertyName != r.cfg.Property {
+			continue
+		}
+		var single string
+		if err := json.Unmarshal(v.Value, &single); err == nil {
+			if single != "" {
+				return []string{single}, nil
+			}
+			continue
+		}
+		var multi []string
+		if err := json.Unmarshal(v.Value, &multi); err == nil {
+			return multi, nil
+		}
+		r.cli.logger.Warn("unsupported owner custom property value", "repository", owner+"/"+repo, "property", v.PropertyName, "value", string(v.Value))
+	}
+	return nil, nil
+}
@@ -0,0 +1,76 @@
+// Copyright 2025 Adevinta
+
+package github
+
+import (
+	"context"
+	"net/http"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/adevinta/ghe-reposec/internal/config"
+)
+
+func TestParseCodeowners(t *testing.T) {
+	codeowners := `# Default owners.
+* @org/team-a @user
+/docs/ @org/docs
+**  @org/team-b # Last matching rule.
+`
+	got, err := parseCodeowners(strings.NewReader(codeowners))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := []string{"org/team-b"}; !slices.Equal(got, want) {
+		t.Errorf("unexpected owners: got %q, want %q", got, want)
+	}
+}
+
+func TestOwnerResolverSources(t *testing.T) {
+	cli := newTestClient(t, config.GHEConfig{}, map[string]http.HandlerFunc{
+		"GET /repos/org/repo/contents/": func(w http.ResponseWriter, _ *http.Request) {
+			writeStatus(w, http.StatusNotFound, "Not Found")
+		},
+		"GET /repos/org/repo/teams": func(w http.ResponseWriter, _ *http.Request) {
+			writeJSON(w, []map[string]any{
+				{"slug": "readers", "permission": "pull"},
+				{"slug": "maintainers", "permission": "maintain"},
+				{"slug": "admins", "permission": "admin"},
+			})
+		},
+		"GET /repos/org/repo/properties/values": func(w http.ResponseWriter, _ *http.Request) {
+			writeJSON(w, []map[string]any{
+				{"property_name": "other", "value": "ignored"},
+				{"property_name": "malformed", "value": 42},
+				{"property_name": "owner", "value": []string{"org/team-a", "org/team-b"}},
+			})
+		},
+	})
+
+	tests := []struct {
+		name     string
+		sources  []string
+		property string
+		want     []string
+	}{
+		{name: "codeowners falls back to teams", sources: []string{OwnerSourceCodeowners, OwnerSourceTeams}, property: "owner", want: []string{"org/admins", "org/maintainers"}},
+		{name: "property", sources: []string{OwnerSourceProperty}, property: "owner", want: []string{"org/team-a", "org/team-b"}},
+		{name: "malformed property falls back to teams", sources: []string{OwnerSourceProperty, OwnerSourceTeams}, property: "malformed", want: []string{"org/admins", "org/maintainers"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, err := NewOwnerResolver(cli, config.OwnersConfig{Sources: tt.sources, Property: tt.property})
+			if err != nil {
+				t.Fatalf("unexpected error creating resolver: %v", err)
+			}
+			repo := Repository{FullName: "org/repo", DefaultBranch: "main"}
+			if err := r.Collect(context.Background(), &repo); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !slices.Equal(repo.Owners, tt.want) {
+				t.Errorf("unexpected owners: got %q, want %q", repo.Owners, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,191 @@
+// Copyright 2025 Adevinta
+
+package github
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	gh "github.com/google/go-github/v67/github"
+)
+
+var (
+	// ErrFileExists is returned when the file to be added by a pull request
+	// already exists in the default branch.
+	ErrFileExists = fmt.Errorf("file already exists in the default branch")
+
+	// ErrBranchModified is returned when the pull request branch already
+	// exists and its head commit was not created by ghe-reposec.
+	ErrBranchModified = fmt.Errorf("branch head commit was not created by ghe-reposec")
+
+	// ErrPullRequestDeclined is returned when a pull request from the same
+	// branch was closed without being merged.
+	ErrPullRequestDeclined = fmt.Errorf("pull request closed without being merged")
+)
+
+// FilePullRequest represents a pull request, managed by ghe-reposec, that
+// adds a file to the default branch of a repository. The pull request is
+// identified by its head branch.
+type FilePullRequest struct {
+	// Base is the default branch of the repository.
+	Base          string
+	Branch        string
+	Title         string
+	Body          string
+	CommitMessage string
+	Path          string
+	Content       []byte
+}
+
+// EnsureFilePullRequest opens the pull request in the repository using the
+// Git Data API, unless there is already an open pull request from the same
+// branch. It returns the pull request number and whether it was created. If
+// a pull request from the same branch was closed without being merged,
+// [ErrPullRequestDeclined] is returned, so declined pull requests are not
+// opened again. If the file already exists in the default branch,
+// [ErrFileExists] is returned. A stale branch without an open pull request is
+// only reset if its head commit was created by the authenticated user,
+// otherwise [ErrBranchModified] is returned.
+func (c *Client) EnsureFilePullRequest(owner, repo string, pr FilePullRequest) (int, bool, error) {
+	if pr.Base == "" {
+		return 0, false, fmt.Errorf("base branch is required")
+	}
+	ctx := context.WithValue(c.ctx, gh.SleepUntilPrimaryRateLimitResetWhenRateLimited, true)
+
+	t := time.Now()
+	prs, _, err := c.client.PullRequests.List(ctx, owner, repo, &gh.PullRequestListOptions{
+		State:       "all",
+		Head:        owner + ":" + pr.Branch,
+		ListOptions: gh.ListOptions{PerPage: 100},
+	})
+	c.apiLatency("list_pull_requests", owner, t, err)
+	if err != nil {
+		return 0, false, fmt.Errorf("failed to list pull requests: %w", err)
+	}
+	for _, p := range prs {
+		if p.GetState() == "open" {
+			return p.GetNumber(), false, nil
+		}
+	}
+	for _, p := range prs {
+		if p.MergedAt == nil {
+			return p.GetNumber(), false, ErrPullRequestDeclined
+		}
+	}
+
+	t = time.Now()
+	_, _, _, err = c.client.Repositories.GetContents(ctx, owner, repo, pr.Path, &gh.RepositoryContentGetOptions{Ref: pr.Base})
+	c.apiLatency("get_contents", owner, t, err)
+	if err == nil {
+		return 0, false, ErrFileExists
+	}
+	if !isNotFound(err) {
+		return 0, false, fmt.Errorf("failed to get %s contents: %w", pr.Path, err)
+	}
+
+	t = time.Now()
+	branchRef, _, err := c.client.Git.GetRef(ctx, owner, repo, "heads/"+pr.Branch)
+	c.apiLatency("get_ref", owner, t, err)
+	exists := err == nil
+	if err != nil && !isNotFound(err) {
+		return 0, false, fmt.Errorf("failed to get branch %s reference: %w", pr.Branch, err)
+	}
+	if exists {
+		t = time.Now()
+		head, _, err := c.client.Repositories.GetCommit(ctx, owner, repo, branchRef.GetObject().GetSHA(), nil)
+		c.apiLatency("get_commit", owner, t, err)
+		if err != nil {
+			return 0, false, fmt.Errorf("failed to get branch %s head commit: %w", pr.Branch, err)
+		}
+		if !strings.EqualFold(head.GetAuthor().GetLogin(), c.login) || !strings.EqualFold(head.GetCommitter().GetLogin(), c.login) {
+			return 0, false, ErrBranchModified
+		}
+	}
+
+	t = time.Now()
+	baseRef, _, err := c.client.Git.GetRef(ctx, owner, repo, "heads/"+pr.Base)
+	c.apiLatency("get_ref", owner, t, err)
+	if err != nil {
+		return 0, false, fmt.Errorf("failed to get default branch reference: %w", err)
+	}
+	t = time.Now()
+	baseCommit, _, err := c.client.Git.GetCommit(ctx, owner, repo, baseRef.GetObject().GetSHA())
+	c.apiLatency("get_git_commit", owner, t, err)
+	if err != nil {
+		return 0, false, fmt.Errorf("failed to get default branch commit: %w", err)
+	}
+
+	t = time.Now()
+	blob, _, err := c.client.Git.CreateBlob(ctx, owner, repo, &gh.Blob{
+		Content:  gh.String(string(pr.Content)),
+		Encoding: gh.String("utf-8"),
+	})
+	c.apiLatency("create_blob", owner, t, err)
+	if err != nil {
+		return 0, false, fmt.Errorf("failed to create blob: %w", err)
+	}
+	t = time.Now()
+	tree, _, err := c.client.Git.CreateTree(ctx, owner, repo, baseCommit.GetTree().GetSHA(), []*gh.TreeEntry{
+		{
+			Path: gh.String(pr.Path),
+			Mode: gh.String("100644"),
+			Type: gh.String("blob"),
+			SHA:  blob.SHA,
+		},
+	})
+	c.apiLatency("create_tree", owner, t, err)
+	if err != nil {
+		return 0, false, fmt.Errorf("failed to create tree: %w", err)
+	}
+	t = time.Now()
+	commit, _, err := c.client.Git.CreateCommit(ctx, owner, repo, &gh.Commit{
+		Message: gh.String(pr.CommitMessage),
+		Tree:    tree,
+		Parents: []*gh.Commit{baseCommit},
+	}, nil)
+	c.apiLatency("create_commit", owner, t, err)
+	if err != nil {
+		return 0, false, fmt.Errorf("failed to create commit: %w", err)
+	}
+
+	// A stale branch created by ghe-reposec without an open pull request is
+	// reset to the new commit.
+	ref := &gh.Reference{
+		Ref:    gh.String("refs/heads/" + pr.Branch),
+		Object: &gh.GitObject{SHA: commit.SHA},
+	}
+	t = time.Now()
+	if exists {
+		_, _, err = c.client.Git.UpdateRef(ctx, owner, repo, ref, true)
+		c.apiLatency("update_ref", owner, t, err)
+	} else {
+		_, _, err = c.client.Git.CreateRef(ctx, owner, repo, ref)
+		c.apiLatency("create_ref", owner, t, err)
+	}
+	if err != nil {
+		return 0, false, fmt.Errorf("failed to set branch %s: %w", pr.Branch, err)
+	}
+
+	t = time.Now()
+	created, _, err := c.client.PullRequests.Create(ctx, owner, repo, &gh.NewPullRequest{
+		Title: gh.String(pr.Title),
+		Head:  gh.String(pr.Branch),
+		Base:  gh.String(pr.Base),
+		Body:  gh.String(pr.Body),
+	})
+	c.apiLatency("create_pull_request", owner, t, err)
+	if err != nil {
+		return 0, false, fmt.Errorf("failed to create pull request: %w", err)
+	}
+
+	return created.GetNumber(), true, nil
+}
+
+func isNotFound(err error) bool {
+	var errResp *gh.ErrorResponse
+	return errors.As(err, &errResp) && errResp.Response != nil && errResp.Response.StatusCode == http.StatusNotFound
+}
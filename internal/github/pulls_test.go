@@ -0,0 +1,152 @@
+// Copyright 2025 Adevinta
+
+package github
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/adevinta/ghe-reposec/internal/config"
+)
+
+func TestEnsureFilePullRequestExistingBranch(t *testing.T) {
+	tests := []struct {
+		name        string
+		headAuthor  string
+		wantErr     error
+		wantUpdates int
+	}{
+		{
+			name:        "branch created by ghe-reposec",
+			headAuthor:  "reposec",
+			wantUpdates: 1,
+		},
+		{
+			name:       "branch modified by others",
+			headAuthor: "someone",
+			wantErr:    ErrBranchModified,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var updates int
+			cli := newTestClient(t, config.GHEConfig{}, map[string]http.HandlerFunc{
+				"GET /repos/org/repo/pulls": func(w http.ResponseWriter, _ *http.Request) {
+					writeJSON(w, []any{})
+				},
+				"GET /repos/org/repo/contents/security.yml": func(w http.ResponseWriter, _ *http.Request) {
+					writeStatus(w, http.StatusNotFound, "Not Found")
+				},
+				"GET /repos/org/repo/git/ref/heads/reposec": func(w http.ResponseWriter, _ *http.Request) {
+					writeJSON(w, map[string]any{"ref": "refs/heads/reposec", "object": map[string]any{"sha": "head"}})
+				},
+				"GET /repos/org/repo/commits/head": func(w http.ResponseWriter, _ *http.Request) {
+					writeJSON(w, map[string]any{
+						"sha":       "head",
+						"author":    map[string]any{"login": tt.headAuthor},
+						"committer": map[string]any{"login": tt.headAuthor},
+					})
+				},
+				"GET /repos/org/repo/git/ref/heads/main": func(w http.ResponseWriter, _ *http.Request) {
+					writeJSON(w, map[string]any{"ref": "refs/heads/main", "object": map[string]any{"sha": "base"}})
+				},
+				"GET /repos/org/repo/git/commits/base": func(w http.ResponseWriter, _ *http.Request) {
+					writeJSON(w, map[string]any{"sha": "base", "tree": map[string]any{"sha": "tree"}})
+				},
+				"POST /repos/org/repo/git/blobs": func(w http.ResponseWriter, _ *http.Request) {
+					writeJSON(w, map[string]any{"sha": "blob"})
+				},
+				"POST /repos/org/repo/git/trees": func(w http.ResponseWriter, _ *http.Request) {
+					writeJSON(w, map[string]any{"sha": "newtree"})
+				},
+				"POST /repos/org/repo/git/commits": func(w http.ResponseWriter, _ *http.Request) {
+					writeJSON(w, map[string]any{"sha": "new"})
+				},
+				"PATCH /repos/org/repo/git/refs/heads/reposec": func(w http.ResponseWriter, _ *http.Request) {
+					updates++
+					writeJSON(w, map[string]any{"ref": "refs/heads/reposec", "object": map[string]any{"sha": "new"}})
+				},
+				"POST /repos/org/repo/pulls": func(w http.ResponseWriter, _ *http.Request) {
+					writeJSON(w, map[string]any{"number": 3})
+				},
+			})
+
+			n, created, err := cli.EnsureFilePullRequest("org", "repo", FilePullRequest{
+				Base:    "main",
+				Branch:  "reposec",
+				Title:   "title",
+				Path:    "security.yml",
+				Content: []byte("content"),
+			})
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("unexpected error: got %v, want %v", err, tt.wantErr)
+			}
+			if updates != tt.wantUpdates {
+				t.Errorf("unexpected branch updates: got %d, want %d", updates, tt.wantUpdates)
+			}
+			if tt.wantErr == nil && (n != 3 || !created) {
+				t.Errorf("unexpected pull request: got %d (created %v), want 3 (created true)", n, created)
+			}
+		})
+	}
+}
+
+func TestEnsureFilePullRequestExistingPullRequests(t *testing.T) {
+	tests := []struct {
+		name        string
+		prs         []map[string]any
+		wantNumber  int
+		wantCreated bool
+		wantErr     error
+	}{
+		{
+			name: "open pull request",
+			prs: []map[string]any{
+				{"number": 1, "state": "closed"},
+				{"number": 2, "state": "open"},
+			},
+			wantNumber: 2,
+		},
+		{
+			name:       "declined pull request",
+			prs:        []map[string]any{{"number": 1, "state": "closed"}},
+			wantNumber: 1,
+			wantErr:    ErrPullRequestDeclined,
+		},
+		{
+			name:    "merged pull request",
+			prs:     []map[string]any{{"number": 1, "state": "closed", "merged_at": "2025-01-02T15:04:05Z"}},
+			wantErr: ErrFileExists,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var query string
+			cli := newTestClient(t, config.GHEConfig{}, map[string]http.HandlerFunc{
+				"GET /repos/org/repo/pulls": func(w http.ResponseWriter, r *http.Request) {
+					query = r.URL.RawQuery
+					writeJSON(w, tt.prs)
+				},
+				"GET /repos/org/repo/contents/security.yml": func(w http.ResponseWriter, _ *http.Request) {
+					writeJSON(w, map[string]any{"type": "file", "name": "security.yml"})
+				},
+			})
+
+			n, created, err := cli.EnsureFilePullRequest("org", "repo", FilePullRequest{
+				Base:   "main",
+				Branch: "reposec",
+				Path:   "security.yml",
+			})
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("unexpected error: got %v, want %v", err, tt.wantErr)
+			}
+			if n != tt.wantNumber || created != tt.wantCreated {
+				t.Errorf("unexpected pull request: got %d (created %v), want %d (created %v)", n, created, tt.wantNumber, tt.wantCreated)
+			}
+			if want := "head=org%3Areposec&per_page=100&state=all"; query != want {
+				t.Errorf("unexpected query: got %q, want %q", query, want)
+			}
+		})
+	}
+}
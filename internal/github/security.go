@@ -0,0 +1,97 @@
+// Copyright 2025 Adevinta
+
+package github
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	gh "github.com/google/go-github/v67/github"
+)
+
+// Security and analysis controls.
+const (
+	ControlSecretScanning            = "secret-scanning"
+	ControlPushProtection            = "secret-scanning-push-protection"
+	ControlDependabotAlerts          = "dependabot-alerts"
+	ControlDependabotSecurityUpdates = "dependabot-security-updates"
+	ControlCodeScanningDefaultSetup  = "code-scanning-default-setup"
+)
+
+// SecurityAnalysis collects the GitHub security features enabled in the
+// repositories and reports them as controls.
+type SecurityAnalysis struct {
+	cli *Client
+}
+
+// NewSecurityAnalysis creates a new security and analysis collector.
+func NewSecurityAnalysis(cli *Client) *SecurityAnalysis {
+	return &SecurityAnalysis{cli: cli}
+}
+
+// Name returns the name of the collector.
+func (s *SecurityAnalysis) Name() string {
+	return "security_analysis"
+}
+
+// Collect adds the GitHub security features enabled in the repository to its
+// Controls field. The secret scanning, push protection and Dependabot
+// security updates status is reported by the repository listing, while the
+// Dependabot alerts and the code scanning default setup status require an
+// additional request each. Every feature is collected independently, so the
+// failure of one of them does not prevent reporting the others.
+func (s *SecurityAnalysis) Collect(ctx context.Context, repo *Repository) error {
+	owner, name, ok := strings.Cut(repo.FullName, "/")
+	if !ok {
+		return fmt.Errorf("invalid repository name: %s", repo.FullName)
+	}
+
+	sa := repo.securityAndAnalysis
+	if sa.GetSecretScanning().GetStatus() == "enabled" {
+		repo.Controls = append(repo.Controls, ControlSecretScanning)
+	}
+	if sa.GetSecretScanningPushProtection().GetStatus() == "enabled" {
+		repo.Controls = append(repo.Controls, ControlPushProtection)
+	}
+	if sa.GetDependabotSecurityUpdates().GetStatus() == "enabled" {
+		repo.Controls = append(repo.Controls, ControlDependabotSecurityUpdates)
+	}
+
+	ctx = context.WithValue(ctx, gh.SleepUntilPrimaryRateLimitResetWhenRateLimited, true)
+	var errs []error
+
+	t := time.Now()
+	enabled, _, err := s.cli.client.Repositories.GetVulnerabilityAlerts(ctx, owner, name)
+	s.cli.apiLatency("get_vulnerability_alerts", owner, t, err)
+	switch {
+	case err != nil:
+		errs = append(errs, fmt.Errorf("failed to get Dependabot alerts status: %w", err))
+	case enabled:
+		repo.Controls = append(repo.Controls, ControlDependabotAlerts)
+	}
+
+	t = time.Now()
+	setup, _, err := s.cli.client.CodeScanning.GetDefaultSetupConfiguration(ctx, owner, name)
+	s.cli.apiLatency("get_code_scanning_default_setup", owner, t, err)
+	switch {
+	case err == nil:
+		if setup.GetState() == "configured" {
+			repo.Controls = append(repo.Controls, ControlCodeScanningDefaultSetup)
+		}
+	case isNotFound(err), isForbidden(err):
+		// Code scanning is not available in the repository.
+	default:
+		errs = append(errs, fmt.Errorf("failed to get code scanning default setup: %w", err))
+	}
+
+	return errors.Join(errs...)
+}
+
+func isForbidden(err error) bool {
+	var errResp *gh.ErrorResponse
+	return errors.As(err, &errResp) && errResp.Response != nil && errResp.Response.StatusCode == http.StatusForbidden
+}
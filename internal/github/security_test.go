@@ -0,0 +1,43 @@
+// Copyright 2025 Adevinta
+
+package github
+
+import (
+	"context"
+	"net/http"
+	"slices"
+	"testing"
+
+	gh "github.com/google/go-github/v67/github"
+
+	"github.com/adevinta/ghe-reposec/internal/config"
+)
+
+func TestSecurityAnalysisCollect(t *testing.T) {
+	cli := newTestClient(t, config.GHEConfig{}, map[string]http.HandlerFunc{
+		"GET /repos/org/repo/vulnerability-alerts": func(w http.ResponseWriter, _ *http.Request) {
+			writeStatus(w, http.StatusInternalServerError, "Server Error")
+		},
+		"GET /repos/org/repo/code-scanning/default-setup": func(w http.ResponseWriter, _ *http.Request) {
+			writeJSON(w, map[string]any{"state": "configured"})
+		},
+	})
+
+	repo := Repository{
+		FullName: "org/repo",
+		securityAndAnalysis: &gh.SecurityAndAnalysis{
+			SecretScanning:               &gh.SecretScanning{Status: gh.String("enabled")},
+			SecretScanningPushProtection: &gh.SecretScanningPushProtection{Status: gh.String("disabled")},
+		},
+	}
+	err := NewSecurityAnalysis(cli).Collect(context.Background(), &repo)
+	if err == nil {
+		t.Error("expected Dependabot alerts status error")
+	}
+	// The code scanning default setup is collected despite the Dependabot
+	// alerts status error.
+	want := []string{ControlSecretScanning, ControlCodeScanningDefaultSetup}
+	if !slices.Equal(repo.Controls, want) {
+		t.Errorf("unexpected controls: got %q, want %q", repo.Controls, want)
+	}
+}
@@ -0,0 +1,186 @@
+// Copyright 2025 Adevinta
+
+package github
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	gh "github.com/google/go-github/v67/github"
+)
+
+// Webhook finding rules.
+const (
+	FindingWebhookHTTPURL          = "webhook-http-url"
+	FindingWebhookInsecureSSL      = "webhook-insecure-ssl"
+	FindingWebhookNoSecret         = "webhook-no-secret"
+	FindingWebhookUnapprovedDomain = "webhook-unapproved-domain"
+)
+
+// Webhooks audits the repository and organization webhooks looking for
+// insecure ones.
+type Webhooks struct {
+	cli            *Client
+	allowedDomains []string
+
+	mu   sync.Mutex
+	orgs map[string]*orgWebhooks
+}
+
+// orgWebhooks are the findings of the webhooks of an organization, which are
+// collected once.
+type orgWebhooks struct {
+	once     sync.Once
+	findings []string
+}
+
+// NewWebhooks creates a new webhooks collector. If allowedDomains is not
+// empty, webhooks pointing to other domains, or their subdomains, are
+// reported.
+func NewWebhooks(cli *Client, allowedDomains []string) *Webhooks {
+	return &Webhooks{
+		cli:            cli,
+		allowedDomains: allowedDomains,
+		orgs:           map[string]*orgWebhooks{},
+	}
+}
+
+// Name returns the name of the collector.
+func (w *Webhooks) Name() string {
+	return "webhooks"
+}
+
+// Collect adds the insecure webhooks of the repository to its Findings field,
+// using the webhook ID as subject. The webhooks of the repository
+// organization are audited the first time one of its repositories is
+// collected, and their findings are also added using "org/<webhook ID>" as
+// subject. They are available through [Webhooks.Organizations] too. Failing
+// to audit the organization webhooks is logged and does not prevent auditing
+// the repository ones.
+func (w *Webhooks) Collect(ctx context.Context, repo *Repository) error {
+	owner, name, ok := strings.Cut(repo.FullName, "/")
+	if !ok {
+		return fmt.Errorf("invalid repository name: %s", repo.FullName)
+	}
+	ctx = context.WithValue(ctx, gh.SleepUntilPrimaryRateLimitResetWhenRateLimited, true)
+
+	w.mu.Lock()
+	org, ok := w.orgs[owner]
+	if !ok {
+		org = &orgWebhooks{}
+		w.orgs[owner] = org
+	}
+	w.mu.Unlock()
+	org.once.Do(func() {
+		findings, err := w.audit(func(opts *gh.ListOptions) ([]*gh.Hook, *gh.Response, error) {
+			t := time.Now()
+			hooks, resp, err := w.cli.client.Organizations.ListHooks(ctx, owner, opts)
+			w.cli.apiLatency("list_organization_hooks", owner, t, err)
+			return hooks, resp, err
+		})
+		switch {
+		case isNotFound(err) || isForbidden(err):
+			// Organization webhooks require an organization owner token.
+			w.cli.logger.Warn("organization webhooks not audited, organization owner access required", "organization", owner, "error", err)
+		case err != nil:
+			w.cli.logger.Error("failed to list organization webhooks", "organization", owner, "error", err)
+		default:
+			org.findings = findings
+		}
+	})
+	for _, f := range org.findings {
+		rule, rest, _ := strings.Cut(f, ":")
+		repo.Findings = append(repo.Findings, rule+":org/"+rest)
+	}
+
+	findings, err := w.audit(func(opts *gh.ListOptions) ([]*gh.Hook, *gh.Response, error) {
+		t := time.Now()
+		hooks, resp, err := w.cli.client.Repositories.ListHooks(ctx, owner, name, opts)
+		w.cli.apiLatency("list_repository_hooks", owner, t, err)
+		return hooks, resp, err
+	})
+	if err != nil {
+		return fmt.Errorf("failed to list repository webhooks: %w", err)
+	}
+	repo.Findings = append(repo.Findings, findings...)
+	return nil
+}
+
+// Organizations returns the findings of the webhooks of the organizations
+// audited so far, keyed by organization.
+func (w *Webhooks) Organizations() map[string][]string {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	findings := map[string][]string{}
+	for org, ow := range w.orgs {
+		findings[org] = ow.findings
+	}
+	return findings
+}
+
+// audit returns the findings of the webhooks returned by the paginated list
+// function.
+func (w *Webhooks) audit(list func(*gh.ListOptions) ([]*gh.Hook, *gh.Response, error)) ([]string, error) {
+	findings := []string{}
+	opts := &gh.ListOptions{PerPage: 100}
+	for {
+		hooks, resp, err := list(opts)
+		if err != nil {
+			return nil, err
+		}
+		for _, h := range hooks {
+			findings = append(findings, w.hookFindings(h)...)
+		}
+		if resp.NextPage == 0 {
+			return findings, nil
+		}
+		opts.Page = resp.NextPage
+	}
+}
+
+func (w *Webhooks) hookFindings(h *gh.Hook) []string {
+	id := strconv.FormatInt(h.GetID(), 10)
+	cfg := h.GetConfig()
+
+	u, err := url.Parse(cfg.GetURL())
+	host := ""
+	if err == nil {
+		host = u.Hostname()
+	}
+
+	findings := []string{}
+	finding := func(rule string) {
+		findings = append(findings, rule+":"+id+":"+host)
+	}
+	if err == nil && strings.EqualFold(u.Scheme, "http") {
+		finding(FindingWebhookHTTPURL)
+	}
+	if cfg.GetInsecureSSL() == "1" {
+		finding(FindingWebhookInsecureSSL)
+	}
+	// The secret is returned masked if configured.
+	if cfg.GetSecret() == "" {
+		finding(FindingWebhookNoSecret)
+	}
+	if len(w.allowedDomains) > 0 && !w.allowed(host) {
+		finding(FindingWebhookUnapprovedDomain)
+	}
+	return findings
+}
+
+// allowed returns whether the host is an allowed domain or one of its
+// subdomains.
+func (w *Webhooks) allowed(host string) bool {
+	host = strings.ToLower(host)
+	return slices.ContainsFunc(w.allowedDomains, func(d string) bool {
+		d = strings.ToLower(strings.TrimPrefix(d, "."))
+		return host == d || strings.HasSuffix(host, "."+d)
+	})
+}
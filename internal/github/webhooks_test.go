@@ -0,0 +1,73 @@
+// Copyright 2025 Adevinta
+
+package github
+
+import (
+	"context"
+	"net/http"
+	"slices"
+	"testing"
+
+	"github.com/adevinta/ghe-reposec/internal/config"
+)
+
+func TestWebhooksCollect(t *testing.T) {
+	repoHooks := func(w http.ResponseWriter, _ *http.Request) {
+		writeJSON(w, []map[string]any{
+			{"id": 1, "config": map[string]any{"url": "http://hooks.example.com", "secret": "********"}},
+		})
+	}
+
+	tests := []struct {
+		name         string
+		orgHooks     http.HandlerFunc
+		wantFindings []string
+		wantOrg      []string
+	}{
+		{
+			name: "organization webhooks",
+			orgHooks: func(w http.ResponseWriter, _ *http.Request) {
+				writeJSON(w, []map[string]any{
+					{"id": 2, "config": map[string]any{"url": "https://hooks.example.com"}},
+				})
+			},
+			wantFindings: []string{"webhook-http-url:1:hooks.example.com", "webhook-no-secret:org/2:hooks.example.com"},
+			wantOrg:      []string{"webhook-no-secret:2:hooks.example.com"},
+		},
+		{
+			name: "forbidden organization webhooks",
+			orgHooks: func(w http.ResponseWriter, _ *http.Request) {
+				writeStatus(w, http.StatusForbidden, "Must have admin rights to Organization.")
+			},
+			wantFindings: []string{"webhook-http-url:1:hooks.example.com"},
+		},
+		{
+			name: "organization webhooks error",
+			orgHooks: func(w http.ResponseWriter, _ *http.Request) {
+				writeStatus(w, http.StatusInternalServerError, "Server Error")
+			},
+			wantFindings: []string{"webhook-http-url:1:hooks.example.com"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cli := newTestClient(t, config.GHEConfig{}, map[string]http.HandlerFunc{
+				"GET /orgs/org/hooks":       tt.orgHooks,
+				"GET /repos/org/repo/hooks": repoHooks,
+			})
+
+			webhooks := NewWebhooks(cli, nil)
+			repo := Repository{FullName: "org/repo"}
+			if err := webhooks.Collect(context.Background(), &repo); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			slices.Sort(repo.Findings)
+			if !slices.Equal(repo.Findings, tt.wantFindings) {
+				t.Errorf("unexpected findings: got %v, want %v", repo.Findings, tt.wantFindings)
+			}
+			if got := webhooks.Organizations()["org"]; !slices.Equal(got, tt.wantOrg) {
+				t.Errorf("unexpected organization findings: got %v, want %v", got, tt.wantOrg)
+			}
+		})
+	}
+}
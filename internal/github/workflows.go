@@ -0,0 +1,207 @@
+// Copyright 2025 Adevinta
+
+package github
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	gh "github.com/google/go-github/v67/github"
+	"gopkg.in/yaml.v3"
+)
+
+// Workflow finding rules.
+const (
+	FindingPullRequestTargetCheckout = "pull-request-target-checkout"
+	FindingUnpinnedAction            = "unpinned-action"
+	FindingWriteAllPermissions       = "write-all-permissions"
+	FindingScriptInjection           = "script-injection"
+)
+
+// workflowsPath is the directory of the GitHub Actions workflows.
+const workflowsPath = ".github/workflows"
+
+var (
+	// shaRef matches a full commit SHA.
+	shaRef = regexp.MustCompile(`^[0-9a-f]{40}$`)
+	// eventExpr matches expressions referencing the event payload.
+	eventExpr = regexp.MustCompile(`\$\{\{[^}]*github\.event\.[^}]*\}\}`)
+	// prHeadExpr matches expressions referencing the head of a pull request.
+	prHeadExpr = regexp.MustCompile(`github\.event\.pull_request\.head\.|github\.head_ref`)
+)
+
+// trustedActionOwners are the owners of the actions that are not considered
+// third-party.
+var trustedActionOwners = []string{"actions", "github"}
+
+// workflow is the subset of a GitHub Actions workflow analyzed.
+type workflow struct {
+	On          yaml.Node              `yaml:"on"`
+	Permissions yaml.Node              `yaml:"permissions"`
+	Jobs        map[string]workflowJob `yaml:"jobs"`
+}
+
+type workflowJob struct {
+	Permissions yaml.Node      `yaml:"permissions"`
+	Uses        string         `yaml:"uses"`
+	Steps       []workflowStep `yaml:"steps"`
+}
+
+type workflowStep struct {
+	Uses string            `yaml:"uses"`
+	Run  string            `yaml:"run"`
+	With map[string]string `yaml:"with"`
+}
+
+// Workflows analyzes the GitHub Actions workflows of the repositories looking
+// for risky patterns.
+type Workflows struct {
+	cli *Client
+}
+
+// NewWorkflows creates a new workflows collector.
+func NewWorkflows(cli *Client) *Workflows {
+	return &Workflows{cli: cli}
+}
+
+// Name returns the name of the collector.
+func (w *Workflows) Name() string {
+	return "workflows"
+}
+
+// Collect adds the risky patterns found in the workflows of the default
+// branch of the repository to its Findings field, using the workflow path as
+// subject.
+func (w *Workflows) Collect(ctx context.Context, repo *Repository) error {
+	owner, name, ok := strings.Cut(repo.FullName, "/")
+	if !ok {
+		return fmt.Errorf("invalid repository name: %s", repo.FullName)
+	}
+	ctx = context.WithValue(ctx, gh.SleepUntilPrimaryRateLimitResetWhenRateLimited, true)
+
+	t := time.Now()
+	_, dir, _, err := w.cli.client.Repositories.GetContents(ctx, owner, name, workflowsPath, &gh.RepositoryContentGetOptions{Ref: repo.DefaultBranch})
+	w.cli.apiLatency("get_contents", owner, t, err)
+	if isNotFound(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to list workflows: %w", err)
+	}
+
+	all := []string{}
+	for _, entry := range dir {
+		ext := path.Ext(entry.GetName())
+		if entry.GetType() != "file" || (ext != ".yml" && ext != ".yaml") {
+			continue
+		}
+		content, ok, err := w.cli.fileContent(ctx, owner, name, entry.GetPath(), repo.DefaultBranch)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			continue
+		}
+		findings, err := analyzeWorkflow(entry.GetPath(), content)
+		if err != nil {
+			w.cli.logger.Warn("failed to parse workflow", "repository", repo.FullName, "workflow", entry.GetPath(), "error", err)
+		}
+		all = append(all, findings...)
+	}
+	slices.Sort(all)
+	repo.Findings = append(repo.Findings, slices.Compact(all)...)
+	return nil
+}
+
+// analyzeWorkflow returns the risky patterns found in a workflow file. When
+// some values of the workflow do not have the expected type, the rest of the
+// workflow is still analyzed and the error is returned with the findings.
+func analyzeWorkflow(file string, content []byte) ([]string, error) {
+	var wf workflow
+	err := yaml.Unmarshal(content, &wf)
+	var typeErr *yaml.TypeError
+	if err != nil && !errors.As(err, &typeErr) {
+		return nil, err
+	}
+
+	findings := []string{}
+	finding := func(rule, detail string) {
+		findings = append(findings, rule+":"+file+":"+detail)
+	}
+
+	if isWriteAll(wf.Permissions) {
+		finding(FindingWriteAllPermissions, "workflow")
+	}
+	prTarget := hasTrigger(wf.On, "pull_request_target")
+	for name, job := range wf.Jobs {
+		if isWriteAll(job.Permissions) {
+			finding(FindingWriteAllPermissions, name)
+		}
+		if job.Uses != "" && !isPinned(job.Uses) {
+			finding(FindingUnpinnedAction, job.Uses)
+		}
+		for _, step := range job.Steps {
+			if step.Uses != "" && !isPinned(step.Uses) {
+				finding(FindingUnpinnedAction, step.Uses)
+			}
+			if prTarget && isCheckout(step.Uses) && prHeadExpr.MatchString(step.With["ref"]) {
+				finding(FindingPullRequestTargetCheckout, name)
+			}
+			for _, expr := range eventExpr.FindAllString(step.Run, -1) {
+				finding(FindingScriptInjection, expr)
+			}
+		}
+	}
+	return findings, err
+}
+
+// hasTrigger returns whether the workflow "on" node, which can be a string, a
+// list or a map, contains the event.
+func hasTrigger(on yaml.Node, event string) bool {
+	switch on.Kind {
+	case yaml.ScalarNode:
+		return on.Value == event
+	case yaml.SequenceNode:
+		return slices.ContainsFunc(on.Content, func(n *yaml.Node) bool { return n.Value == event })
+	case yaml.MappingNode:
+		for i := 0; i < len(on.Content); i += 2 {
+			if on.Content[i].Value == event {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func isWriteAll(permissions yaml.Node) bool {
+	return permissions.Kind == yaml.ScalarNode && permissions.Value == "write-all"
+}
+
+// isPinned returns whether the action reference is pinned to a commit SHA.
+// Local actions, Docker images and actions owned by GitHub are considered
+// pinned.
+func isPinned(uses string) bool {
+	if strings.HasPrefix(uses, "./") || strings.HasPrefix(uses, "docker://") {
+		return true
+	}
+	action, ref, ok := strings.Cut(uses, "@")
+	if !ok {
+		return false
+	}
+	owner, _, _ := strings.Cut(action, "/")
+	if slices.Contains(trustedActionOwners, strings.ToLower(owner)) {
+		return true
+	}
+	return shaRef.MatchString(ref)
+}
+
+func isCheckout(uses string) bool {
+	action, _, _ := strings.Cut(uses, "@")
+	return strings.EqualFold(action, "actions/checkout")
+}
@@ -0,0 +1,107 @@
+// Copyright 2025 Adevinta
+
+package github
+
+import (
+	"context"
+	"encoding/base64"
+	"net/http"
+	"slices"
+	"testing"
+
+	"github.com/adevinta/ghe-reposec/internal/config"
+)
+
+func TestAnalyzeWorkflow(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    []string
+		wantErr bool
+	}{
+		{
+			name: "risky patterns",
+			content: `
+on: pull_request_target
+permissions: write-all
+jobs:
+  build:
+    steps:
+      - uses: actions/checkout@v4
+        with:
+          ref: ${{ github.event.pull_request.head.sha }}
+      - uses: third/action@v1
+      - run: echo "${{ github.event.issue.title }}"
+`,
+			want: []string{
+				"write-all-permissions:ci.yml:workflow",
+				"pull-request-target-checkout:ci.yml:build",
+				"unpinned-action:ci.yml:third/action@v1",
+				"script-injection:ci.yml:${{ github.event.issue.title }}",
+			},
+		},
+		{
+			name: "type error",
+			content: `
+jobs:
+  build:
+    steps:
+      - uses: third/action@v1
+        with:
+          args: [a, b]
+`,
+			want:    []string{"unpinned-action:ci.yml:third/action@v1"},
+			wantErr: true,
+		},
+		{
+			name:    "invalid yaml",
+			content: "jobs: [",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := analyzeWorkflow("ci.yml", []byte(tt.content))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			slices.Sort(got)
+			slices.Sort(tt.want)
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("unexpected findings:\ngot:  %v\nwant: %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWorkflowsCollect(t *testing.T) {
+	workflow := base64.StdEncoding.EncodeToString([]byte(`
+jobs:
+  build:
+    steps:
+      - uses: third/action@v1
+      - uses: third/action@v1
+`))
+	cli := newTestClient(t, config.GHEConfig{}, map[string]http.HandlerFunc{
+		"GET /repos/org/repo/contents/.github/workflows": func(w http.ResponseWriter, _ *http.Request) {
+			writeJSON(w, []map[string]any{
+				{"type": "file", "name": "ci.yml", "path": ".github/workflows/ci.yml"},
+				{"type": "file", "name": "README.md", "path": ".github/workflows/README.md"},
+			})
+		},
+		"GET /repos/org/repo/contents/.github/workflows/ci.yml": func(w http.ResponseWriter, _ *http.Request) {
+			writeJSON(w, map[string]any{"type": "file", "name": "ci.yml", "encoding": "base64", "content": workflow})
+		},
+	})
+
+	// The findings of other collectors are kept as they are.
+	existing := []string{"write-deploy-key:2:age=1d", "outside-collaborator:user:push", "outside-collaborator:user:push"}
+	repo := Repository{FullName: "org/repo", DefaultBranch: "main", Findings: slices.Clone(existing)}
+	if err := NewWorkflows(cli).Collect(context.Background(), &repo); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := append(existing, "unpinned-action:.github/workflows/ci.yml:third/action@v1")
+	if !slices.Equal(repo.Findings, want) {
+		t.Errorf("unexpected findings:\ngot:  %q\nwant: %q", repo.Findings, want)
+	}
+}
@@ -12,13 +12,19 @@ import (
 	"net/url"
 	"os"
 	"os/exec"
+	"slices"
 	"strings"
 	"sync"
 	"time"
 
 	report "github.com/adevinta/vulcan-report"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
 
 	"github.com/adevinta/ghe-reposec/internal/config"
+	"github.com/adevinta/ghe-reposec/internal/metrics"
+	"github.com/adevinta/ghe-reposec/internal/tracing"
 )
 
 var (
@@ -42,17 +48,69 @@ type Summary struct {
 	ControlInPlace   bool
 	NumberOfControls int
 	Error            string
+	PolicyStatus     string
+	MissingControls  []string
+	WaiverStatus     string
+	Score            float64
+	Owners           []string
+	// Alerts are the number of open security alerts keyed by
+	// "tool:severity".
+	Alerts map[string]int
+	// Violations are the reasons, other than missing controls, that make
+	// the summary non-compliant.
+	Violations []string
+	// Findings are the risky patterns found outside Lava, formatted as
+	// "rule:subject:detail".
+	Findings []string
+}
+
+// AddControl adds a control found outside Lava to the summary, unless it is
+// already present. The control only makes the summary to have a control in
+// place if inPlace is true.
+func (s *Summary) AddControl(control string, inPlace bool) {
+	if slices.Contains(s.Controls, control) {
+		return
+	}
+	s.Controls = append(s.Controls, control)
+	s.NumberOfControls++
+	if inPlace {
+		s.ControlInPlace = true
+	}
+}
+
+// Organization returns the organization of the summary repository or an empty
+// string if it can not be determined.
+func (s Summary) Organization() string {
+	org, _, err := orgAndRepo(s.Repository)
+	if err != nil {
+		return ""
+	}
+	return org
+}
+
+// FullName returns the "organization/repository" name of the summary
+// repository or an empty string if it can not be determined.
+func (s Summary) FullName() string {
+	org, repo, err := orgAndRepo(s.Repository)
+	if err != nil {
+		return ""
+	}
+	return org + "/" + strings.TrimSuffix(repo, ".git")
 }
 
 // Client is a Lava client wrapper.
 type Client struct {
-	cfg    config.LavaConfig
-	logger *slog.Logger
-	ctx    context.Context
+	cfg     config.LavaConfig
+	logger  *slog.Logger
+	metrics *metrics.Client
+	ctx     context.Context
+
+	mu          sync.Mutex
+	resultFiles []string
 }
 
 // NewClient creates a new Lava client.
-func NewClient(ctx context.Context, logger *slog.Logger, cfg config.LavaConfig) (*Client, error) {
+func NewClient(ctx context.Context, logger *slog.Logger, m *metrics.Client, cfg config.LavaConfig) (*Client, error) {
 	if cfg.Token == "" {
 		return nil, ErrTokenRequired
 	}
@@ -70,14 +128,21 @@ func NewClient(ctx context.Context, logger *slog.Logger, cfg config.LavaConfig)
 	}
 
 	return &Client{
-		cfg:    cfg,
-		logger: logger,
-		ctx:    ctx,
+		cfg:     cfg,
+		logger:  logger,
+		metrics: m,
+		ctx:     ctx,
 	}, nil
 }
 
-// Scan runs a Lava scan against the provided repositories.
-func (c *Client) Scan(targets []string) []Summary {
+// Handler is called for every repository summary as soon as its scan
+// completes.
+type Handler func(s *Summary)
+
+// Scan runs a Lava scan against the provided repositories. The provided
+// handlers are called sequentially, in order, for each summary as soon as it
+// is available.
+func (c *Client) Scan(targets []string, handlers ...Handler) []Summary {
 	c.logger.Debug("start scanning repositories")
 
 	jobsChan := make(chan string, len(targets))
@@ -93,12 +158,17 @@ func (c *Client) Scan(targets []string) []Summary {
 	}
 	close(jobsChan)
 
-	wg.Wait()
-	close(jobResultsChan)
+	go func() {
+		wg.Wait()
+		close(jobResultsChan)
+	}()
 
 	summary := []Summary{}
 	for rs := range jobResultsChan {
 		for _, s := range rs {
+			for _, h := range handlers {
+				h(&s)
+			}
 			summary = append(summary, s)
 			c.logger.Info(
 				"live repository summary",
@@ -128,6 +198,20 @@ func (c *Client) scanRepo(repo string) []Summary {
 	t := time.Now()
 	c.logger.Debug("repository scan started", "repository", repo)
 
+	org, _, _ := orgAndRepo(repo)
+	orgTag := fmt.Sprintf("organization:%s", org)
+	status := "success"
+
+	ctx, span := tracing.Tracer().Start(c.ctx, "lava.scan_repository",
+		trace.WithAttributes(attribute.String("repository", repo)),
+	)
+	defer func() {
+		d := time.Since(t)
+		c.metrics.Distribution("scan.duration", d.Seconds(), []string{orgTag, fmt.Sprintf("status:%s", status)})
+		span.SetAttributes(attribute.Float64("duration_seconds", d.Seconds()))
+		span.End()
+	}()
+
 	lavaCmdArgs := []string{
 		"run",
 		"-var", fmt.Sprintf("GITHUB_ENTERPRISE_ENDPOINT=%s", c.cfg.BaseURL),
@@ -141,16 +225,21 @@ func (c *Client) scanRepo(repo string) []Summary {
 
 	c.logger.Debug("scan repository command", "repository", repo, "args", strings.Replace(strings.Join(lavaCmdArgs, " "), c.cfg.Token, "REDACTED", -1))
 
-	cmd := exec.CommandContext(c.ctx, c.cfg.BinaryPath, lavaCmdArgs...)
+	cmd := exec.CommandContext(ctx, c.cfg.BinaryPath, lavaCmdArgs...)
 	var outBuf, errBuf bytes.Buffer
 	cmd.Stdout = &outBuf
 	cmd.Stderr = &errBuf
 
 	err := cmd.Run()
 	c.storeResults(repo, outBuf.Bytes(), errBuf.Bytes())
+	exitCode := cmd.ProcessState.ExitCode()
+	span.SetAttributes(attribute.Int("lava.exit_code", exitCode))
+	c.metrics.Count("scan.exit_code", 1, []string{orgTag, fmt.Sprintf("exit_code:%d", exitCode)})
 
-	if cmd.ProcessState.ExitCode() > 0 {
+	if exitCode > 0 {
 		c.logger.Error("failed to run Lava", "error", err, "repository", repo, "stderr", errBuf.String(), "stdout", outBuf.String(), "duration", time.Since(t).Seconds())
+		span.SetStatus(codes.Error, "error running Lava")
+		status = "lava_error"
 		summary = append(summary, Summary{Repository: repo, Error: fmt.Sprintf("error running Lava: %s", err.Error())})
 		return summary
 	}
@@ -158,6 +247,10 @@ func (c *Client) scanRepo(repo string) []Summary {
 	var lr []report.Vulnerability
 	if err := json.Unmarshal(outBuf.Bytes(), &lr); err != nil {
 		c.logger.Error("failed to unmarshal Lava report", "error", err, "repository", repo, "stderr", errBuf.String(), "stdout", outBuf.String(), "duration", time.Since(t).Seconds())
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "error unmarshalling Lava report")
+		c.metrics.Count("scan.parse_failures", 1, []string{orgTag})
+		status = "parse_error"
 		summary = append(summary, Summary{Repository: repo, Error: fmt.Sprintf("error unmarsalling Lava report: %s", err.Error())})
 		return summary
 	}
@@ -217,9 +310,21 @@ func (c *Client) storeResults(target string, stdout, stderr []byte) {
 		return
 	}
 
+	c.mu.Lock()
+	c.resultFiles = append(c.resultFiles, stdOutFile, stdErrFile)
+	c.mu.Unlock()
+
 	c.logger.Debug("Lava scan results stored", "repository", target, "stdout", stdOutFile, "stderr", stdErrFile)
 }
 
+// ResultFiles returns the Lava results files written under the configured
+// results path by the scans of this client.
+func (c *Client) ResultFiles() []string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return slices.Clone(c.resultFiles)
+}
+
 func orgAndRepo(target string) (string, string, error) {
 	parsedURL, err := url.Parse(target)
 	if err != nil {
@@ -1,14 +1,13 @@
 // Copyright 2025 Adevinta
 
-// Package metrics provides a wrapper to interact with StatsD.
+// Package metrics provides a wrapper to interact with metrics services.
 package metrics
 
 import (
 	"context"
 	"fmt"
 	"log/slog"
-
-	"github.com/DataDog/datadog-go/statsd"
+	"time"
 
 	"github.com/adevinta/ghe-reposec/internal/config"
 )
@@ -17,6 +16,10 @@ var (
 	// ClientNotInitializedMsg is logged when the metrics client is not
 	// initialized and metrics are enabled.
 	ClientNotInitializedMsg = "metrics client not initialized"
+
+	// ErrUnsupportedBackend is returned when the metrics backend is not
+	// supported.
+	ErrUnsupportedBackend = fmt.Errorf("unsupported metrics backend")
 )
 
 const (
@@ -24,12 +27,24 @@ const (
 	DefaultMetricsClientAddr = "localhost:8125"
 )
 
+// Backend represents a metrics service backend.
+type Backend interface {
+	Gauge(name string, value float64, tags []string) error
+	Count(name string, value int64, tags []string) error
+	Histogram(name string, value float64, tags []string) error
+	Distribution(name string, value float64, tags []string) error
+	Timing(name string, value time.Duration, tags []string) error
+	ServiceCheck(name string, status byte, message string, tags []string) error
+	Flush() error
+	Close() error
+}
+
 // Client represents a metrics service client.
 type Client struct {
-	cfg    config.MetricsConfig
-	client *statsd.Client
-	logger *slog.Logger
-	ctx    context.Context
+	cfg     config.MetricsConfig
+	backend Backend
+	logger  *slog.Logger
+	ctx     context.Context
 }
 
 // NewClient creates a new metrics client based on environment variables config.
@@ -38,37 +53,50 @@ func NewClient(ctx context.Context, logger *slog.Logger, cfg config.MetricsConfi
 		logger.Info("metrics reporting disabled")
 		return &Client{}, nil
 	}
-	address := cfg.Address
-	if address == "" {
-		logger.Warn("metrics address not provided, using default", "address", DefaultMetricsClientAddr)
-		address = DefaultMetricsClientAddr
-	}
 
-	statsd, err := statsd.New(address)
+	var (
+		backend Backend
+		err     error
+	)
+	switch cfg.Backend {
+	case "statsd":
+		address := cfg.Address
+		if address == "" {
+			logger.Warn("metrics address not provided, using default", "address", DefaultMetricsClientAddr)
+			address = DefaultMetricsClientAddr
+		}
+		backend, err = newStatsdBackend(address)
+	case "prometheus":
+		backend, err = newPrometheusBackend(logger, cfg)
+	default:
+		err = fmt.Errorf("%w: %s", ErrUnsupportedBackend, cfg.Backend)
+	}
 	if err != nil {
 		return nil, err
 	}
 
 	return &Client{
-		cfg:    cfg,
-		client: statsd,
-		logger: logger,
-		ctx:    ctx,
+		cfg:     cfg,
+		backend: backend,
+		logger:  logger,
+		ctx:     ctx,
 	}, nil
 }
 
 // Gauge sends a gauge metric to the metrics service.
 func (c *Client) Gauge(name string, value int, tags []string) {
-	if !c.cfg.Enabled {
-		return
-	}
-	if c.client == nil {
-		c.logger.Warn(ClientNotInitializedMsg)
+	c.GaugeFloat(name, float64(value), tags)
+}
+
+// GaugeFloat sends a gauge metric with a fractional value to the metrics
+// service.
+func (c *Client) GaugeFloat(name string, value float64, tags []string) {
+	if !c.ready() {
 		return
 	}
 	tags = append(tags, c.cfg.Tags...)
 	name = fmt.Sprintf("%s.%s", c.cfg.Namespace, name)
-	err := c.client.Gauge(name, float64(value), tags, 1)
+	err := c.backend.Gauge(name, value, tags)
 	if err != nil {
 		c.logger.Error("gauge metric push error", "error", err)
 		return
@@ -76,23 +104,76 @@ func (c *Client) Gauge(name string, value int, tags []string) {
 	c.logger.Debug("gauge metric pushed", "name", name, "value", value, "tags", tags)
 }
 
-// ServiceCheck sends a service satus signal to the metrics service.
-func (c *Client) ServiceCheck(status byte, message string, tags []string) {
-	if !c.cfg.Enabled {
+// Count increments a counter metric in the metrics service.
+func (c *Client) Count(name string, value int64, tags []string) {
+	if !c.ready() {
 		return
 	}
-	if c.client == nil {
-		c.logger.Warn(ClientNotInitializedMsg)
+	tags = append(tags, c.cfg.Tags...)
+	name = fmt.Sprintf("%s.%s", c.cfg.Namespace, name)
+	err := c.backend.Count(name, value, tags)
+	if err != nil {
+		c.logger.Error("count metric push error", "error", err)
+		return
+	}
+	c.logger.Debug("count metric pushed", "name", name, "value", value, "tags", tags)
+}
+
+// Histogram sends a histogram sample to the metrics service. Histograms are
+// aggregated by the metrics agent.
+func (c *Client) Histogram(name string, value float64, tags []string) {
+	if !c.ready() {
+		return
+	}
+	tags = append(tags, c.cfg.Tags...)
+	name = fmt.Sprintf("%s.%s", c.cfg.Namespace, name)
+	err := c.backend.Histogram(name, value, tags)
+	if err != nil {
+		c.logger.Error("histogram metric push error", "error", err)
+		return
+	}
+	c.logger.Debug("histogram metric pushed", "name", name, "value", value, "tags", tags)
+}
+
+// Distribution sends a distribution sample to the metrics service.
+// Distributions are aggregated globally by the metrics service.
+func (c *Client) Distribution(name string, value float64, tags []string) {
+	if !c.ready() {
+		return
+	}
+	tags = append(tags, c.cfg.Tags...)
+	name = fmt.Sprintf("%s.%s", c.cfg.Namespace, name)
+	err := c.backend.Distribution(name, value, tags)
+	if err != nil {
+		c.logger.Error("distribution metric push error", "error", err)
+		return
+	}
+	c.logger.Debug("distribution metric pushed", "name", name, "value", value, "tags", tags)
+}
+
+// Timing sends a duration sample to the metrics service.
+func (c *Client) Timing(name string, value time.Duration, tags []string) {
+	if !c.ready() {
+		return
+	}
+	tags = append(tags, c.cfg.Tags...)
+	name = fmt.Sprintf("%s.%s", c.cfg.Namespace, name)
+	err := c.backend.Timing(name, value, tags)
+	if err != nil {
+		c.logger.Error("timing metric push error", "error", err)
+		return
+	}
+	c.logger.Debug("timing metric pushed", "name", name, "value", value, "tags", tags)
+}
+
+// ServiceCheck sends a service satus signal to the metrics service.
+func (c *Client) ServiceCheck(status byte, message string, tags []string) {
+	if !c.ready() {
 		return
 	}
 	tags = append(tags, c.cfg.Tags...)
 	name := fmt.Sprintf("%s.service_check", c.cfg.Namespace)
-	err := c.client.ServiceCheck(&statsd.ServiceCheck{
-		Name:    name,
-		Status:  statsd.ServiceCheckStatus(status),
-		Tags:    tags,
-		Message: message,
-	})
+	err := c.backend.ServiceCheck(name, status, message, tags)
 	if err != nil {
 		c.logger.Error("service check push error", "error", err)
 		return
@@ -102,14 +183,10 @@ func (c *Client) ServiceCheck(status byte, message string, tags []string) {
 
 // Close closes the metrics client.
 func (c *Client) Close() {
-	if !c.cfg.Enabled {
-		return
-	}
-	if c.client == nil {
-		c.logger.Warn(ClientNotInitializedMsg)
+	if !c.ready() {
 		return
 	}
-	err := c.client.Close()
+	err := c.backend.Close()
 	if err != nil {
 		c.logger.Error("metrics client close error", "error", err)
 		return
@@ -119,17 +196,25 @@ func (c *Client) Close() {
 
 // Flush flushes the metrics client.
 func (c *Client) Flush() {
-	if !c.cfg.Enabled {
-		return
-	}
-	if c.client == nil {
-		c.logger.Warn(ClientNotInitializedMsg)
+	if !c.ready() {
 		return
 	}
-	err := c.client.Flush()
+	err := c.backend.Flush()
 	if err != nil {
 		c.logger.Error("metrics client flush error", "error", err)
 		return
 	}
 	c.logger.Debug("metrics client flushed")
 }
+
+// ready returns whether metrics are enabled and the client is initialized.
+func (c *Client) ready() bool {
+	if !c.cfg.Enabled {
+		return false
+	}
+	if c.backend == nil {
+		c.logger.Warn(ClientNotInitializedMsg)
+		return false
+	}
+	return true
+}
@@ -0,0 +1,269 @@
+// Copyright 2025 Adevinta
+
+package metrics
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/prometheus/client_golang/prometheus/push"
+
+	"github.com/adevinta/ghe-reposec/internal/config"
+)
+
+var (
+	invalidNameChars = regexp.MustCompile(`[^a-zA-Z0-9_]`)
+
+	// histogramBuckets cover from milliseconds API calls to hours long
+	// scans.
+	histogramBuckets = prometheus.ExponentialBuckets(0.005, 2, 22)
+)
+
+// prometheusBackend is a Prometheus metrics backend. Metrics are served by
+// an HTTP endpoint during the execution and, optionally, pushed to a
+// Pushgateway when the backend is closed.
+type prometheusBackend struct {
+	cfg       config.MetricsConfig
+	logger    *slog.Logger
+	registry  *prometheus.Registry
+	collector *collector
+	server    *http.Server
+}
+
+func newPrometheusBackend(logger *slog.Logger, cfg config.MetricsConfig) (*prometheusBackend, error) {
+	registry := prometheus.NewRegistry()
+	c := &collector{series: map[string]*series{}}
+	if err := registry.Register(c); err != nil {
+		return nil, err
+	}
+
+	b := &prometheusBackend{
+		cfg:       cfg,
+		logger:    logger,
+		registry:  registry,
+		collector: c,
+	}
+
+	if cfg.PrometheusListenAddress != "" {
+		mux := http.NewServeMux()
+		mux.Handle("/metrics", promhttp.HandlerFor(registry, promhttp.HandlerOpts{}))
+		b.server = &http.Server{
+			Addr:              cfg.PrometheusListenAddress,
+			Handler:           mux,
+			ReadHeaderTimeout: 10 * time.Second,
+		}
+		go func() {
+			err := b.server.ListenAndServe()
+			if err != nil && !errors.Is(err, http.ErrServerClosed) {
+				logger.Error("prometheus metrics server error", "error", err)
+			}
+		}()
+		logger.Info("serving prometheus metrics", "address", cfg.PrometheusListenAddress)
+	}
+
+	return b, nil
+}
+
+func (b *prometheusBackend) Gauge(name string, value float64, tags []string) error {
+	b.collector.set(name, value, tags)
+	return nil
+}
+
+// Count is exposed as a counter with the "_total" suffix required by the
+// Prometheus naming conventions.
+func (b *prometheusBackend) Count(name string, value int64, tags []string) error {
+	if !strings.HasSuffix(name, "_total") {
+		name += "_total"
+	}
+	b.collector.add(name, float64(value), tags)
+	return nil
+}
+
+func (b *prometheusBackend) Histogram(name string, value float64, tags []string) error {
+	b.collector.observe(name, value, tags)
+	return nil
+}
+
+// Distribution is exposed as a histogram, as Prometheus aggregates them
+// globally at query time.
+func (b *prometheusBackend) Distribution(name string, value float64, tags []string) error {
+	b.collector.observe(name, value, tags)
+	return nil
+}
+
+// Timing is exposed as a histogram in seconds.
+func (b *prometheusBackend) Timing(name string, value time.Duration, tags []string) error {
+	b.collector.observe(name+"_seconds", value.Seconds(), tags)
+	return nil
+}
+
+// ServiceCheck exposes the service check status as a gauge.
+func (b *prometheusBackend) ServiceCheck(name string, status byte, _ string, tags []string) error {
+	b.collector.set(name, float64(status), tags)
+	return nil
+}
+
+// Flush is a no-op, metrics are gathered when scraped or pushed.
+func (b *prometheusBackend) Flush() error {
+	return nil
+}
+
+// Close pushes the metrics to the Pushgateway, if configured, and stops the
+// metrics HTTP server.
+func (b *prometheusBackend) Close() error {
+	var errs []error
+	if b.cfg.PrometheusPushgatewayURL != "" {
+		err := push.New(b.cfg.PrometheusPushgatewayURL, b.cfg.PrometheusJob).Gatherer(b.registry).Push()
+		if err != nil {
+			errs = append(errs, err)
+		} else {
+			b.logger.Debug("prometheus metrics pushed", "url", b.cfg.PrometheusPushgatewayURL)
+		}
+	}
+	if b.server != nil {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		errs = append(errs, b.server.Shutdown(ctx))
+	}
+	return errors.Join(errs...)
+}
+
+// seriesKind is the kind of a metric series.
+type seriesKind int
+
+const (
+	gaugeKind seriesKind = iota
+	counterKind
+	histogramKind
+)
+
+// series is a metric series identified by its name and labels.
+type series struct {
+	name        string
+	labelNames  []string
+	labelValues []string
+	kind        seriesKind
+	value       float64
+	count       uint64
+	buckets     map[float64]uint64
+}
+
+// collector is an unchecked Prometheus collector that exposes the gauges,
+// counters and histograms of every series. It allows metrics to be defined
+// dynamically from StatsD-like tags.
+type collector struct {
+	mu     sync.Mutex
+	series map[string]*series
+}
+
+// get returns the series for the provided name and tags, creating it if
+// needed. The caller must hold the lock.
+func (c *collector) get(name string, kind seriesKind, tags []string) *series {
+	s := newSeries(name, kind, tags)
+	key := s.name + "{" + strings.Join(s.labelNames, ",") + "=" + strings.Join(s.labelValues, ",") + "}"
+	if existing, ok := c.series[key]; ok && existing.kind == kind {
+		return existing
+	}
+	c.series[key] = s
+	return s
+}
+
+func (c *collector) set(name string, value float64, tags []string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.get(name, gaugeKind, tags).value = value
+}
+
+func (c *collector) add(name string, value float64, tags []string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.get(name, counterKind, tags).value += value
+}
+
+func (c *collector) observe(name string, value float64, tags []string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	s := c.get(name, histogramKind, tags)
+	s.count++
+	s.value += value
+	for _, b := range histogramBuckets {
+		if value <= b {
+			s.buckets[b]++
+		}
+	}
+}
+
+// Describe does not send any descriptor so the collector is unchecked.
+func (c *collector) Describe(chan<- *prometheus.Desc) {}
+
+func (c *collector) Collect(ch chan<- prometheus.Metric) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, s := range c.series {
+		desc := prometheus.NewDesc(s.name, "Metric reported by ghe-reposec.", s.labelNames, nil)
+		var (
+			m   prometheus.Metric
+			err error
+		)
+		switch s.kind {
+		case counterKind:
+			m, err = prometheus.NewConstMetric(desc, prometheus.CounterValue, s.value, s.labelValues...)
+		case histogramKind:
+			buckets := make(map[float64]uint64, len(s.buckets))
+			for b, n := range s.buckets {
+				buckets[b] = n
+			}
+			m, err = prometheus.NewConstHistogram(desc, s.count, s.value, buckets, s.labelValues...)
+		default:
+			m, err = prometheus.NewConstMetric(desc, prometheus.GaugeValue, s.value, s.labelValues...)
+		}
+		if err != nil {
+			ch <- prometheus.NewInvalidMetric(desc, err)
+			continue
+		}
+		ch <- m
+	}
+}
+
+// newSeries converts a StatsD-like metric name and its "key:value" tags into
+// a Prometheus series.
+func newSeries(name string, kind seriesKind, tags []string) *series {
+	labels := map[string]string{}
+	for _, t := range tags {
+		if t == "" {
+			continue
+		}
+		k, v, _ := strings.Cut(t, ":")
+		labels[sanitizeName(k)] = v
+	}
+
+	s := &series{name: sanitizeName(name), kind: kind}
+	if kind == histogramKind {
+		s.buckets = map[float64]uint64{}
+	}
+	for k := range labels {
+		s.labelNames = append(s.labelNames, k)
+	}
+	sort.Strings(s.labelNames)
+	for _, k := range s.labelNames {
+		s.labelValues = append(s.labelValues, labels[k])
+	}
+	return s
+}
+
+func sanitizeName(name string) string {
+	name = invalidNameChars.ReplaceAllString(name, "_")
+	if name != "" && name[0] >= '0' && name[0] <= '9' {
+		name = "_" + name
+	}
+	return name
+}
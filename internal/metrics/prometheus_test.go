@@ -0,0 +1,49 @@
+// Copyright 2025 Adevinta
+
+package metrics
+
+import (
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/adevinta/ghe-reposec/internal/config"
+)
+
+func TestPrometheusBackend(t *testing.T) {
+	b, err := newPrometheusBackend(slog.Default(), config.MetricsConfig{})
+	if err != nil {
+		t.Fatalf("unexpected error creating backend: %v", err)
+	}
+
+	b.Count("ghereposec.scan.exit_code", 1, []string{"exit_code:0", "organization:org"})
+	b.Count("ghereposec.scan.exit_code", 2, []string{"exit_code:0", "organization:org"})
+	b.Timing("ghereposec.github.api.latency", time.Second, []string{"endpoint:list_organizations", "organization:"})
+	b.Timing("ghereposec.github.api.latency", time.Second, []string{"endpoint:list_repositories", "organization:org"})
+
+	families, err := b.registry.Gather()
+	if err != nil {
+		t.Fatalf("unexpected error gathering metrics: %v", err)
+	}
+	got := map[string]int{}
+	for _, f := range families {
+		got[f.GetName()] = len(f.GetMetric())
+		for _, m := range f.GetMetric() {
+			if f.GetName() == "ghereposec_scan_exit_code_total" && m.GetCounter().GetValue() != 3 {
+				t.Errorf("unexpected counter value: got %v, want 3", m.GetCounter().GetValue())
+			}
+			if f.GetName() == "ghereposec_github_api_latency_seconds" && len(m.GetLabel()) != 2 {
+				t.Errorf("unexpected labels: %v", m.GetLabel())
+			}
+		}
+	}
+	want := map[string]int{
+		"ghereposec_scan_exit_code_total":       1,
+		"ghereposec_github_api_latency_seconds": 2,
+	}
+	for name, n := range want {
+		if got[name] != n {
+			t.Errorf("unexpected number of %s series: got %d, want %d", name, got[name], n)
+		}
+	}
+}
@@ -0,0 +1,59 @@
+// Copyright 2025 Adevinta
+
+package metrics
+
+import (
+	"time"
+
+	"github.com/DataDog/datadog-go/statsd"
+)
+
+// statsdBackend is a DogStatsD metrics backend.
+type statsdBackend struct {
+	client *statsd.Client
+}
+
+func newStatsdBackend(address string) (*statsdBackend, error) {
+	client, err := statsd.New(address)
+	if err != nil {
+		return nil, err
+	}
+	return &statsdBackend{client: client}, nil
+}
+
+func (b *statsdBackend) Gauge(name string, value float64, tags []string) error {
+	return b.client.Gauge(name, value, tags, 1)
+}
+
+func (b *statsdBackend) Count(name string, value int64, tags []string) error {
+	return b.client.Count(name, value, tags, 1)
+}
+
+func (b *statsdBackend) Histogram(name string, value float64, tags []string) error {
+	return b.client.Histogram(name, value, tags, 1)
+}
+
+func (b *statsdBackend) Distribution(name string, value float64, tags []string) error {
+	return b.client.Distribution(name, value, tags, 1)
+}
+
+func (b *statsdBackend) Timing(name string, value time.Duration, tags []string) error {
+	return b.client.Timing(name, value, tags, 1)
+}
+
+func (b *statsdBackend) ServiceCheck(name string, status byte, message string, tags []string) error {
+	return b.client.ServiceCheck(&statsd.ServiceCheck{
+		Name:    name,
+		Status:  statsd.ServiceCheckStatus(status),
+		Tags:    tags,
+		Message: message,
+	})
+}
+
+func (b *statsdBackend) Flush() error {
+	return b.client.Flush()
+}
+
+func (b *statsdBackend) Close() error {
+	return b.client.Close()
+}
@@ -0,0 +1,390 @@
+// Copyright 2025 Adevinta
+
+package notify
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	htmltemplate "html/template"
+	"log/slog"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"net/textproto"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+	"text/template"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/adevinta/ghe-reposec/internal/config"
+	"github.com/adevinta/ghe-reposec/internal/lava"
+	"github.com/adevinta/ghe-reposec/internal/metrics"
+	"github.com/adevinta/ghe-reposec/internal/policy"
+)
+
+var (
+	// ErrEmailFromRequired is returned when the email sender is not provided.
+	ErrEmailFromRequired = fmt.Errorf("email sender address is required")
+	// ErrSMTPAddressRequired is returned when neither the SMTP server address
+	// nor the dry-run path are provided.
+	ErrSMTPAddressRequired = fmt.Errorf("SMTP server address is required")
+)
+
+// DefaultEmailTextTemplate is the default email plain text body template.
+const DefaultEmailTextTemplate = `The latest security controls scan reported {{len .Repositories}} repositories
+owned by you that require attention.
+{{range .Repositories}}
+- {{.FullName}} ({{.Status}})
+{{- if .MissingControls}}
+  Missing controls: {{join .MissingControls ", "}}
+{{- end}}
+{{- if .Violations}}
+  Violations: {{join .Violations ", "}}
+{{- end}}
+{{- if .Error}}
+  Scan error: {{.Error}}
+{{- end}}
+{{- end}}
+`
+
+// DefaultEmailHTMLTemplate is the default email HTML body template.
+const DefaultEmailHTMLTemplate = `<html>
+<body>
+<p>The latest security controls scan reported {{len .Repositories}} repositories owned by you that require attention.</p>
+<table border="1" cellpadding="4" cellspacing="0">
+<tr><th>Repository</th><th>Status</th><th>Missing controls</th><th>Violations</th><th>Scan error</th></tr>
+{{- range .Repositories}}
+<tr><td>{{.FullName}}</td><td>{{.Status}}</td><td>{{join .MissingControls ", "}}</td><td>{{join .Violations ", "}}</td><td>{{.Error}}</td></tr>
+{{- end}}
+</table>
+</body>
+</html>
+`
+
+// Recipient routes the digest of the repositories owned by the matching
+// owners to email addresses.
+type Recipient struct {
+	// Owners are glob patterns matching the repository owners, e.g.
+	// "organization/team".
+	Owners []string `yaml:"owners"`
+	Emails []string `yaml:"emails"`
+}
+
+// Recipients is the owners to email addresses mapping.
+type Recipients struct {
+	Recipients []Recipient `yaml:"recipients"`
+	// Default are the email addresses receiving the repositories whose
+	// owners do not match any recipient. Optional.
+	Default []string `yaml:"default"`
+}
+
+// LoadRecipients reads a YAML owners to email addresses mapping file.
+func LoadRecipients(file string) (*Recipients, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read recipients file: %w", err)
+	}
+
+	var r Recipients
+	if err := yaml.Unmarshal(data, &r); err != nil {
+		return nil, fmt.Errorf("failed to parse recipients file: %w", err)
+	}
+	for i, rc := range r.Recipients {
+		if len(rc.Owners) == 0 || len(rc.Emails) == 0 {
+			return nil, fmt.Errorf("recipient %d must have owners and emails", i)
+		}
+		for _, o := range rc.Owners {
+			if _, err := path.Match(o, ""); err != nil {
+				return nil, fmt.Errorf("invalid owner pattern %q in recipient %d: %w", o, i, err)
+			}
+		}
+		if err := validateEmails(rc.Emails); err != nil {
+			return nil, fmt.Errorf("invalid email in recipient %d: %w", i, err)
+		}
+	}
+	if err := validateEmails(r.Default); err != nil {
+		return nil, fmt.Errorf("invalid default email: %w", err)
+	}
+
+	return &r, nil
+}
+
+func validateEmails(emails []string) error {
+	for _, e := range emails {
+		if _, err := mail.ParseAddress(e); err != nil {
+			return fmt.Errorf("%q: %w", e, err)
+		}
+	}
+	return nil
+}
+
+// route returns the email addresses of the provided owners. Addresses are
+// normalized, lowercase and without display names, so every recipient gets a
+// single digest.
+func (r *Recipients) route(owners []string) []string {
+	emails := []string{}
+	add := func(list []string) {
+		for _, e := range list {
+			addr, err := mail.ParseAddress(e)
+			if err != nil {
+				continue
+			}
+			if a := strings.ToLower(addr.Address); !slices.Contains(emails, a) {
+				emails = append(emails, a)
+			}
+		}
+	}
+	for _, rc := range r.Recipients {
+		if matchOwners(rc.Owners, owners) {
+			add(rc.Emails)
+		}
+	}
+	if len(emails) == 0 {
+		add(r.Default)
+	}
+	return emails
+}
+
+// emailData is the data available to the email body templates.
+type emailData struct {
+	Recipient    string
+	Repositories []emailRepository
+}
+
+// emailRepository is a repository listed in an email digest.
+type emailRepository struct {
+	FullName        string
+	Status          string
+	MissingControls []string
+	Violations      []string
+	Error           string
+	Owners          []string
+}
+
+// Email sends digests of the non-compliant and erroring repositories to the
+// email addresses of their owners.
+type Email struct {
+	cfg        config.NotifyConfig
+	logger     *slog.Logger
+	metrics    *metrics.Client
+	recipients *Recipients
+	text       *template.Template
+	html       *htmltemplate.Template
+}
+
+// NewEmail creates a new email notifier.
+func NewEmail(logger *slog.Logger, m *metrics.Client, cfg config.NotifyConfig) (*Email, error) {
+	if cfg.EmailFrom == "" {
+		return nil, ErrEmailFromRequired
+	}
+	if _, err := mail.ParseAddress(cfg.EmailFrom); err != nil {
+		return nil, fmt.Errorf("invalid email sender address: %w", err)
+	}
+	if cfg.SMTPAddress == "" && cfg.EmailDryRunPath == "" {
+		return nil, ErrSMTPAddressRequired
+	}
+	recipients, err := LoadRecipients(cfg.EmailRecipientsFile)
+	if err != nil {
+		return nil, err
+	}
+	funcs := map[string]any{"join": strings.Join}
+
+	textTmpl, err := readTemplate(cfg.EmailTextTemplate, DefaultEmailTextTemplate)
+	if err != nil {
+		return nil, err
+	}
+	text, err := template.New("text").Funcs(funcs).Parse(textTmpl)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse email text template: %w", err)
+	}
+	htmlTmpl, err := readTemplate(cfg.EmailHTMLTemplate, DefaultEmailHTMLTemplate)
+	if err != nil {
+		return nil, err
+	}
+	html, err := htmltemplate.New("html").Funcs(funcs).Parse(htmlTmpl)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse email HTML template: %w", err)
+	}
+	return &Email{
+		cfg:        cfg,
+		logger:     logger,
+		metrics:    m,
+		recipients: recipients,
+		text:       text,
+		html:       html,
+	}, nil
+}
+
+func readTemplate(file, def string) (string, error) {
+	if file == "" {
+		return def, nil
+	}
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return "", fmt.Errorf("failed to read email template: %w", err)
+	}
+	return string(data), nil
+}
+
+// Notify sends an email digest of the non-compliant and erroring
+// repositories to each of their owners recipients. If a dry-run path is
+// configured, the emails are written there as .eml files instead.
+func (n *Email) Notify(summary []lava.Summary) {
+	n.logger.Debug("notifying owners via email")
+
+	digests := map[string][]lava.Summary{}
+	results := map[string]int{
+		"sent":     0,
+		"failed":   0,
+		"unrouted": 0,
+	}
+	for _, s := range filter(summary, isNonCompliantOrError) {
+		emails := n.recipients.route(s.Owners)
+		if len(emails) == 0 {
+			n.logger.Debug("no recipient for repository owners", "repository", s.Repository, "owners", s.Owners)
+			results["unrouted"]++
+			continue
+		}
+		for _, email := range emails {
+			digests[email] = append(digests[email], s)
+		}
+	}
+
+	for rcpt, entries := range digests {
+		msg, err := n.message(rcpt, entries)
+		if err == nil {
+			err = n.send(rcpt, msg)
+		}
+		if err != nil {
+			n.logger.Error("failed to send email digest", "recipient", rcpt, "repositories", len(entries), "error", err)
+			results["failed"]++
+			continue
+		}
+		results["sent"]++
+	}
+	for k, v := range results {
+		n.metrics.Gauge("notifications.email", v, []string{fmt.Sprintf("status:%s", k)})
+	}
+	n.logger.Info("email notifications completed", "results", results, "dry_run", n.cfg.EmailDryRunPath != "")
+}
+
+// message renders the MIME message of the digest sent to rcpt.
+func (n *Email) message(rcpt string, entries []lava.Summary) ([]byte, error) {
+	sortByName(entries)
+	data := emailData{Recipient: rcpt}
+	for _, e := range entries {
+		status := e.PolicyStatus
+		if e.Error != "" {
+			status = policy.StatusError
+		}
+		data.Repositories = append(data.Repositories, emailRepository{
+			FullName:        e.FullName(),
+			Status:          status,
+			MissingControls: describeControls(e.MissingControls),
+			Violations:      e.Violations,
+			Error:           e.Error,
+			Owners:          e.Owners,
+		})
+	}
+
+	var text, html bytes.Buffer
+	if err := n.text.Execute(&text, data); err != nil {
+		return nil, fmt.Errorf("failed to render email text template: %w", err)
+	}
+	if err := n.html.Execute(&html, data); err != nil {
+		return nil, fmt.Errorf("failed to render email HTML template: %w", err)
+	}
+
+	var msg bytes.Buffer
+	mw := multipart.NewWriter(&msg)
+	id := make([]byte, 16)
+	if _, err := rand.Read(id); err != nil {
+		return nil, err
+	}
+	domain := "localhost"
+	if _, d, ok := strings.Cut(n.cfg.EmailFrom, "@"); ok {
+		domain = strings.TrimSuffix(d, ">")
+	}
+	headers := []string{
+		"From: " + n.cfg.EmailFrom,
+		"To: " + rcpt,
+		"Subject: " + mime.QEncoding.Encode("utf-8", n.cfg.EmailSubject),
+		"Date: " + time.Now().Format(time.RFC1123Z),
+		fmt.Sprintf("Message-ID: <%s@%s>", hex.EncodeToString(id), domain),
+		"MIME-Version: 1.0",
+		"Content-Type: multipart/alternative; boundary=" + mw.Boundary(),
+	}
+	msg.WriteString(strings.Join(headers, "\r\n") + "\r\n\r\n")
+
+	for _, part := range []struct {
+		contentType string
+		body        []byte
+	}{
+		{"text/plain; charset=utf-8", text.Bytes()},
+		{"text/html; charset=utf-8", html.Bytes()},
+	} {
+		pw, err := mw.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {part.contentType},
+			"Content-Transfer-Encoding": {"quoted-printable"},
+		})
+		if err != nil {
+			return nil, err
+		}
+		qw := quotedprintable.NewWriter(pw)
+		if _, err := qw.Write(part.body); err != nil {
+			return nil, err
+		}
+		if err := qw.Close(); err != nil {
+			return nil, err
+		}
+	}
+	if err := mw.Close(); err != nil {
+		return nil, err
+	}
+	return msg.Bytes(), nil
+}
+
+// unsafeFileChars matches the characters not allowed in dry-run file names.
+var unsafeFileChars = regexp.MustCompile(`[^A-Za-z0-9._@-]`)
+
+func (n *Email) send(rcpt string, msg []byte) error {
+	addr, err := mail.ParseAddress(rcpt)
+	if err != nil {
+		return err
+	}
+
+	if n.cfg.EmailDryRunPath != "" {
+		if err := os.MkdirAll(n.cfg.EmailDryRunPath, 0755); err != nil {
+			return err
+		}
+		file := filepath.Join(n.cfg.EmailDryRunPath, unsafeFileChars.ReplaceAllString(addr.Address, "_")+".eml")
+		// The emails contain the recipient addresses, so they are only
+		// readable by the owner.
+		return os.WriteFile(file, msg, 0600)
+	}
+
+	from, err := mail.ParseAddress(n.cfg.EmailFrom)
+	if err != nil {
+		return err
+	}
+	var auth smtp.Auth
+	if n.cfg.SMTPUsername != "" {
+		host, _, err := net.SplitHostPort(n.cfg.SMTPAddress)
+		if err != nil {
+			return fmt.Errorf("invalid SMTP server address: %w", err)
+		}
+		auth = smtp.PlainAuth("", n.cfg.SMTPUsername, n.cfg.SMTPPassword, host)
+	}
+	return smtp.SendMail(n.cfg.SMTPAddress, auth, from.Address, []string{addr.Address}, msg)
+}
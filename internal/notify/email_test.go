@@ -0,0 +1,204 @@
+// Copyright 2025 Adevinta
+
+package notify
+
+import (
+	"net"
+	"net/textproto"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/adevinta/ghe-reposec/internal/config"
+	"github.com/adevinta/ghe-reposec/internal/lava"
+	"github.com/adevinta/ghe-reposec/internal/policy"
+)
+
+// smtpMessage is a message received by the SMTP stand-in.
+type smtpMessage struct {
+	from string
+	to   []string
+	data string
+}
+
+// newSMTPServer starts a minimal SMTP server stand-in that accepts every
+// message. It returns its address and a function returning the received
+// messages.
+func newSMTPServer(t *testing.T) (string, func() []smtpMessage) {
+	t.Helper()
+
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("unexpected error listening: %v", err)
+	}
+	t.Cleanup(func() { l.Close() })
+
+	var (
+		mu       sync.Mutex
+		wg       sync.WaitGroup
+		messages []smtpMessage
+	)
+	go func() {
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				defer conn.Close()
+
+				tp := textproto.NewConn(conn)
+				tp.PrintfLine("220 localhost ESMTP")
+				var msg smtpMessage
+				for {
+					line, err := tp.ReadLine()
+					if err != nil {
+						return
+					}
+					cmd := strings.ToUpper(strings.SplitN(line, " ", 2)[0])
+					switch cmd {
+					case "EHLO", "HELO":
+						tp.PrintfLine("250 localhost")
+					case "MAIL":
+						msg.from = strings.Trim(strings.TrimPrefix(line, "MAIL FROM:"), "<>")
+						tp.PrintfLine("250 OK")
+					case "RCPT":
+						msg.to = append(msg.to, strings.Trim(strings.TrimPrefix(line, "RCPT TO:"), "<>"))
+						tp.PrintfLine("250 OK")
+					case "DATA":
+						tp.PrintfLine("354 Go ahead")
+						data, err := tp.ReadDotBytes()
+						if err != nil {
+							return
+						}
+						msg.data = string(data)
+						mu.Lock()
+						messages = append(messages, msg)
+						mu.Unlock()
+						msg = smtpMessage{}
+						tp.PrintfLine("250 OK")
+					case "QUIT":
+						tp.PrintfLine("221 Bye")
+						return
+					default:
+						tp.PrintfLine("250 OK")
+					}
+				}
+			}()
+		}
+	}()
+
+	return l.Addr().String(), func() []smtpMessage {
+		l.Close()
+		wg.Wait()
+		mu.Lock()
+		defer mu.Unlock()
+		return slices.Clone(messages)
+	}
+}
+
+func writeRecipients(t *testing.T) string {
+	t.Helper()
+
+	file := filepath.Join(t.TempDir(), "recipients.yaml")
+	recipients := `recipients:
+  - owners: ["org/team-a"]
+    emails: ["Team A <team-a@example.com>", "security@example.com"]
+  - owners: ["org/team-b"]
+    emails: ["Security Team <Security@example.com>"]
+`
+	if err := os.WriteFile(file, []byte(recipients), 0644); err != nil {
+		t.Fatalf("unexpected error writing recipients file: %v", err)
+	}
+	return file
+}
+
+var emailSummary = []lava.Summary{
+	{Repository: "https://ghe.example.com/org/a.git", PolicyStatus: policy.StatusNonCompliant, Owners: []string{"org/team-a"}, MissingControls: []string{"sast"}},
+	{Repository: "https://ghe.example.com/org/b.git", PolicyStatus: policy.StatusError, Error: "error running Lava", Owners: []string{"org/team-b"}},
+	{Repository: "https://ghe.example.com/org/c.git", PolicyStatus: policy.StatusCompliant, Owners: []string{"org/team-a"}},
+}
+
+func TestEmailNotifySMTP(t *testing.T) {
+	addr, received := newSMTPServer(t)
+	logger, m := newTestMetrics(t)
+	email, err := NewEmail(logger, m, config.NotifyConfig{
+		EmailRecipientsFile: writeRecipients(t),
+		EmailFrom:           "Reposec <reposec@example.com>",
+		EmailSubject:        "Digest",
+		SMTPAddress:         addr,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error creating notifier: %v", err)
+	}
+
+	email.Notify(emailSummary)
+
+	messages := received()
+	got := map[string]string{}
+	for _, msg := range messages {
+		if msg.from != "reposec@example.com" {
+			t.Errorf("unexpected sender: %q", msg.from)
+		}
+		for _, to := range msg.to {
+			got[to] = msg.data
+		}
+	}
+	if len(messages) != 2 || len(got) != 2 {
+		t.Fatalf("unexpected messages: %+v", messages)
+	}
+	if data := got["team-a@example.com"]; !strings.Contains(data, "org/a") || strings.Contains(data, "org/c") {
+		t.Errorf("unexpected team A digest: %q", data)
+	}
+	// security@example.com owns both repositories, so it gets one digest
+	// listing both.
+	if data := got["security@example.com"]; !strings.Contains(data, "org/a") || !strings.Contains(data, "org/b") {
+		t.Errorf("unexpected security digest: %q", data)
+	}
+}
+
+func TestEmailNotifyDryRun(t *testing.T) {
+	dir := t.TempDir()
+	logger, m := newTestMetrics(t)
+	email, err := NewEmail(logger, m, config.NotifyConfig{
+		EmailRecipientsFile: writeRecipients(t),
+		EmailFrom:           "reposec@example.com",
+		EmailDryRunPath:     dir,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error creating notifier: %v", err)
+	}
+
+	email.Notify(emailSummary)
+
+	files, err := filepath.Glob(filepath.Join(dir, "*.eml"))
+	if err != nil {
+		t.Fatalf("unexpected error listing files: %v", err)
+	}
+	for i := range files {
+		files[i] = filepath.Base(files[i])
+	}
+	slices.Sort(files)
+	if want := []string{"security@example.com.eml", "team-a@example.com.eml"}; !slices.Equal(files, want) {
+		t.Fatalf("unexpected files: got %q, want %q", files, want)
+	}
+	info, err := os.Stat(filepath.Join(dir, "security@example.com.eml"))
+	if err != nil {
+		t.Fatalf("unexpected error reading file info: %v", err)
+	}
+	if perm := info.Mode().Perm(); perm != 0600 {
+		t.Errorf("unexpected file permissions: got %o, want %o", perm, 0600)
+	}
+	data, err := os.ReadFile(filepath.Join(dir, "security@example.com.eml"))
+	if err != nil {
+		t.Fatalf("unexpected error reading file: %v", err)
+	}
+	if !strings.Contains(string(data), "org/a") || !strings.Contains(string(data), "org/b") {
+		t.Errorf("unexpected security digest: %q", data)
+	}
+}
@@ -0,0 +1,68 @@
+// Copyright 2025 Adevinta
+
+// Package notify implements digests of the non-compliant repositories sent to
+// their owners.
+package notify
+
+import (
+	"path"
+	"slices"
+	"strings"
+
+	"github.com/adevinta/ghe-reposec/internal/lava"
+	"github.com/adevinta/ghe-reposec/internal/policy"
+)
+
+// matchOwners returns whether any of the owners matches any of the glob
+// patterns.
+func matchOwners(patterns, owners []string) bool {
+	return slices.ContainsFunc(owners, func(o string) bool {
+		return slices.ContainsFunc(patterns, func(p string) bool {
+			ok, _ := path.Match(strings.ToLower(p), strings.ToLower(o))
+			return ok
+		})
+	})
+}
+
+// filter returns the summaries for which keep returns true.
+func filter(summary []lava.Summary, keep func(lava.Summary) bool) []lava.Summary {
+	ret := []lava.Summary{}
+	for _, s := range summary {
+		if keep(s) {
+			ret = append(ret, s)
+		}
+	}
+	return ret
+}
+
+func isNonCompliant(s lava.Summary) bool {
+	return s.PolicyStatus == policy.StatusNonCompliant
+}
+
+func isNonCompliantOrError(s lava.Summary) bool {
+	return s.PolicyStatus == policy.StatusNonCompliant || s.Error != ""
+}
+
+// sortByName sorts the summaries by repository full name.
+func sortByName(summary []lava.Summary) {
+	slices.SortFunc(summary, func(a, b lava.Summary) int {
+		return strings.Compare(a.FullName(), b.FullName())
+	})
+}
+
+// describeControls returns a human readable description of the missing
+// controls as reported by the policy.
+func describeControls(controls []string) []string {
+	ret := make([]string, 0, len(controls))
+	for _, c := range controls {
+		switch alternatives := strings.Split(c, "|"); {
+		case c == policy.AnyControl:
+			ret = append(ret, "any control")
+		case len(alternatives) > 1:
+			ret = append(ret, "one of "+strings.Join(alternatives, "/"))
+		default:
+			ret = append(ret, c)
+		}
+	}
+	return ret
+}
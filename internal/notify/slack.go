@@ -0,0 +1,186 @@
+// Copyright 2025 Adevinta
+
+package notify
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+	"path"
+	"slices"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/adevinta/ghe-reposec/internal/config"
+	"github.com/adevinta/ghe-reposec/internal/lava"
+	"github.com/adevinta/ghe-reposec/internal/metrics"
+)
+
+// maxDigestRepositories is the maximum number of repositories listed in a
+// digest message.
+const maxDigestRepositories = 50
+
+// Webhook routes the digest of the repositories owned by the matching owners
+// to an incoming webhook.
+type Webhook struct {
+	// Owners are glob patterns matching the repository owners, e.g.
+	// "organization/team".
+	Owners []string `yaml:"owners"`
+	URL    string   `yaml:"url"`
+}
+
+// Webhooks is the owners to incoming webhooks mapping.
+type Webhooks struct {
+	Webhooks []Webhook `yaml:"webhooks"`
+	// Default is the incoming webhook receiving the repositories whose
+	// owners do not match any webhook. Optional.
+	Default string `yaml:"default"`
+}
+
+// LoadWebhooks reads a YAML owners to incoming webhooks mapping file.
+func LoadWebhooks(file string) (*Webhooks, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read webhooks file: %w", err)
+	}
+
+	var w Webhooks
+	if err := yaml.Unmarshal(data, &w); err != nil {
+		return nil, fmt.Errorf("failed to parse webhooks file: %w", err)
+	}
+	for i, wh := range w.Webhooks {
+		if len(wh.Owners) == 0 || wh.URL == "" {
+			return nil, fmt.Errorf("webhook %d must have owners and an URL", i)
+		}
+		for _, o := range wh.Owners {
+			if _, err := path.Match(o, ""); err != nil {
+				return nil, fmt.Errorf("invalid owner pattern %q in webhook %d: %w", o, i, err)
+			}
+		}
+	}
+
+	return &w, nil
+}
+
+// route returns the incoming webhooks of the provided owners.
+func (w *Webhooks) route(owners []string) []string {
+	urls := []string{}
+	for _, wh := range w.Webhooks {
+		if matchOwners(wh.Owners, owners) && !slices.Contains(urls, wh.URL) {
+			urls = append(urls, wh.URL)
+		}
+	}
+	if len(urls) == 0 && w.Default != "" {
+		urls = append(urls, w.Default)
+	}
+	return urls
+}
+
+// Slack posts digests of the non-compliant repositories to Slack compatible
+// incoming webhooks.
+type Slack struct {
+	cfg      config.NotifyConfig
+	logger   *slog.Logger
+	metrics  *metrics.Client
+	webhooks *Webhooks
+	client   *http.Client
+}
+
+// NewSlack creates a new Slack notifier.
+func NewSlack(logger *slog.Logger, m *metrics.Client, cfg config.NotifyConfig) (*Slack, error) {
+	webhooks, err := LoadWebhooks(cfg.SlackWebhooksFile)
+	if err != nil {
+		return nil, err
+	}
+	return &Slack{
+		cfg:      cfg,
+		logger:   logger,
+		metrics:  m,
+		webhooks: webhooks,
+		client:   &http.Client{Timeout: 30 * time.Second},
+	}, nil
+}
+
+// Notify posts a digest of the non-compliant repositories to the incoming
+// webhook of each of their owners.
+func (n *Slack) Notify(summary []lava.Summary) {
+	n.logger.Debug("notifying owners via Slack webhooks")
+
+	digests := map[string][]lava.Summary{}
+	results := map[string]int{
+		"sent":     0,
+		"failed":   0,
+		"unrouted": 0,
+	}
+	for _, s := range filter(summary, isNonCompliant) {
+		urls := n.webhooks.route(s.Owners)
+		if len(urls) == 0 {
+			n.logger.Debug("no webhook for repository owners", "repository", s.Repository, "owners", s.Owners)
+			results["unrouted"]++
+			continue
+		}
+		for _, url := range urls {
+			digests[url] = append(digests[url], s)
+		}
+	}
+
+	for url, entries := range digests {
+		if err := n.post(url, slackDigest(entries)); err != nil {
+			n.logger.Error("failed to post Slack digest", "repositories", len(entries), "error", err)
+			results["failed"]++
+			continue
+		}
+		results["sent"]++
+	}
+	for k, v := range results {
+		n.metrics.Gauge("notifications.slack", v, []string{fmt.Sprintf("status:%s", k)})
+	}
+	n.logger.Info("Slack notifications completed", "results", results)
+}
+
+func (n *Slack) post(url, text string) error {
+	payload, err := json.Marshal(map[string]string{"text": text})
+	if err != nil {
+		return err
+	}
+	resp, err := n.client.Post(url, "application/json", bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// slackDigest renders the digest message using Slack mrkdwn.
+func slackDigest(entries []lava.Summary) string {
+	sortByName(entries)
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "*%d non-compliant repositories* reported by the latest security controls scan:\n", len(entries))
+	for i, e := range entries {
+		if i == maxDigestRepositories {
+			fmt.Fprintf(&sb, "…and %d more.\n", len(entries)-maxDigestRepositories)
+			break
+		}
+		fmt.Fprintf(&sb, "• `%s`", e.FullName())
+		if len(e.MissingControls) > 0 {
+			fmt.Fprintf(&sb, " missing: %s", strings.Join(describeControls(e.MissingControls), ", "))
+		}
+		if len(e.Violations) > 0 {
+			fmt.Fprintf(&sb, " violations: %s", strings.Join(e.Violations, ", "))
+		}
+		if len(e.Owners) > 0 {
+			fmt.Fprintf(&sb, " (owners: %s)", strings.Join(e.Owners, ", "))
+		}
+		sb.WriteString("\n")
+	}
+	return sb.String()
+}
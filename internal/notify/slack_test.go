@@ -0,0 +1,90 @@
+// Copyright 2025 Adevinta
+
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/adevinta/ghe-reposec/internal/config"
+	"github.com/adevinta/ghe-reposec/internal/lava"
+	"github.com/adevinta/ghe-reposec/internal/metrics"
+	"github.com/adevinta/ghe-reposec/internal/policy"
+)
+
+func newTestMetrics(t *testing.T) (*slog.Logger, *metrics.Client) {
+	t.Helper()
+
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	m, err := metrics.NewClient(context.Background(), logger, config.MetricsConfig{})
+	if err != nil {
+		t.Fatalf("unexpected error creating metrics client: %v", err)
+	}
+	return logger, m
+}
+
+func TestSlackNotify(t *testing.T) {
+	var (
+		mu       sync.Mutex
+		messages = map[string]string{}
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var payload map[string]string
+		if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		mu.Lock()
+		messages[r.URL.Path] = payload["text"]
+		mu.Unlock()
+		if r.URL.Path == "/failing" {
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}))
+	defer srv.Close()
+
+	file := filepath.Join(t.TempDir(), "webhooks.yaml")
+	webhooks := fmt.Sprintf(`webhooks:
+  - owners: ["org/team-*"]
+    url: %[1]s/teams
+  - owners: ["org/failing"]
+    url: %[1]s/failing
+default: %[1]s/default
+`, srv.URL)
+	if err := os.WriteFile(file, []byte(webhooks), 0644); err != nil {
+		t.Fatalf("unexpected error writing webhooks file: %v", err)
+	}
+
+	logger, m := newTestMetrics(t)
+	slack, err := NewSlack(logger, m, config.NotifyConfig{SlackWebhooksFile: file})
+	if err != nil {
+		t.Fatalf("unexpected error creating notifier: %v", err)
+	}
+
+	slack.Notify([]lava.Summary{
+		{Repository: "https://ghe.example.com/org/a.git", PolicyStatus: policy.StatusNonCompliant, Owners: []string{"org/team-a"}, MissingControls: []string{"sast|sca"}},
+		{Repository: "https://ghe.example.com/org/b.git", PolicyStatus: policy.StatusNonCompliant, Owners: []string{"someone"}},
+		{Repository: "https://ghe.example.com/org/c.git", PolicyStatus: policy.StatusCompliant, Owners: []string{"org/team-c"}},
+		{Repository: "https://ghe.example.com/org/d.git", PolicyStatus: policy.StatusNonCompliant, Owners: []string{"org/failing"}},
+	})
+
+	if len(messages) != 3 {
+		t.Fatalf("unexpected number of digests: got %d, want 3: %v", len(messages), messages)
+	}
+	if msg := messages["/teams"]; !strings.Contains(msg, "`org/a` missing: one of sast/sca") || strings.Contains(msg, "org/c") {
+		t.Errorf("unexpected teams digest: %q", msg)
+	}
+	if msg := messages["/default"]; !strings.Contains(msg, "`org/b`") || strings.Contains(msg, "org/a") {
+		t.Errorf("unexpected default digest: %q", msg)
+	}
+}
@@ -7,7 +7,10 @@ import (
 	"encoding/csv"
 	"encoding/json"
 	"fmt"
+	"io"
 	"os"
+	"path/filepath"
+	"slices"
 	"strconv"
 	"strings"
 
@@ -22,22 +25,120 @@ var (
 	ErrOutputFileRequired = fmt.Errorf("output file is required and was not provided")
 )
 
-// Write writes the output of the ghe-reposec tool.
+// PrevSuffix is appended to the output file path to keep the output of the
+// previous execution.
+const PrevSuffix = ".prev"
+
+// Write writes the output of the ghe-reposec tool. The output is written to a
+// temporary file in the same directory that replaces the output file only
+// when it has been completely written. The previous output file, if any, is
+// kept with the [PrevSuffix] suffix.
 func Write(format, file string, summary []lava.Summary) error {
 	if file == "" {
 		return ErrOutputFileRequired
 	}
+	if !supportedFormat(format) {
+		return ErrUnsupportedFormat
+	}
 
-	f, err := os.Create(file)
+	f, err := os.CreateTemp(filepath.Dir(file), "."+filepath.Base(file)+".*.tmp")
 	if err != nil {
 		return err
 	}
-	defer f.Close()
+	tmp := f.Name()
+
+	if err := writeFile(f, format, summary); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+
+	if err := keepPrevious(file); err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("failed to keep previous output: %w", err)
+	}
 
+	if err := os.Rename(tmp, file); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+
+	return nil
+}
+
+func supportedFormat(format string) bool {
+	switch strings.ToLower(format) {
+	case "csv", "json", "ndjson":
+		return true
+	default:
+		return false
+	}
+}
+
+// writeFile encodes the summary into f, syncs it to disk and closes it.
+func writeFile(f *os.File, format string, summary []lava.Summary) error {
+	if err := encode(f, format, summary); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Chmod(0644); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// keepPrevious hard links the current output file, if any, to its
+// [PrevSuffix] path so the output file can be atomically replaced. If the
+// file system does not support hard links, the file is copied instead.
+func keepPrevious(file string) error {
+	if _, err := os.Stat(file); err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+
+	prev := file + PrevSuffix
+	if err := os.Remove(prev); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	if err := os.Link(file, prev); err != nil {
+		return copyFile(file, prev)
+	}
+	return nil
+}
+
+// copyFile copies the src file into a new dst file and syncs it to disk.
+func copyFile(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.OpenFile(dst, os.O_CREATE|os.O_WRONLY|os.O_EXCL, 0644)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	if err := out.Sync(); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
+
+func encode(w io.Writer, format string, summary []lava.Summary) error {
 	switch strings.ToLower(format) {
 	case "csv":
-		writer := csv.NewWriter(f)
-		defer writer.Flush()
+		writer := csv.NewWriter(w)
 
 		err := writer.Write(
 			[]string{
@@ -46,6 +147,14 @@ func Write(format, file string, summary []lava.Summary) error {
 				"number_of_controls",
 				"controls",
 				"error",
+				"policy_status",
+				"missing_controls",
+				"waiver_status",
+				"score",
+				"owners",
+				"alerts",
+				"violations",
+				"findings",
 			},
 		)
 		if err != nil {
@@ -59,22 +168,47 @@ func Write(format, file string, summary []lava.Summary) error {
 					strconv.Itoa(s.NumberOfControls),
 					strings.Join(s.Controls, "#"),
 					s.Error,
+					s.PolicyStatus,
+					strings.Join(s.MissingControls, "#"),
+					s.WaiverStatus,
+					strconv.FormatFloat(s.Score, 'f', -1, 64),
+					strings.Join(s.Owners, "#"),
+					formatCounts(s.Alerts),
+					strings.Join(s.Violations, "#"),
+					strings.Join(s.Findings, "#"),
 				},
 			)
 			if err != nil {
 				return err
 			}
 		}
+		writer.Flush()
+		return writer.Error()
 	case "json":
-		encoder := json.NewEncoder(f)
+		encoder := json.NewEncoder(w)
 		encoder.SetIndent("", "  ")
-		err := encoder.Encode(summary)
-		if err != nil {
-			return err
+		return encoder.Encode(summary)
+	case "ndjson":
+		encoder := json.NewEncoder(w)
+		for _, s := range summary {
+			err := encoder.Encode(s)
+			if err != nil {
+				return err
+			}
 		}
+		return nil
 	default:
 		return ErrUnsupportedFormat
 	}
+}
 
-	return nil
+// formatCounts formats counts as "key=value" pairs sorted by key and joined
+// by "#".
+func formatCounts(counts map[string]int) string {
+	pairs := make([]string, 0, len(counts))
+	for k, v := range counts {
+		pairs = append(pairs, k+"="+strconv.Itoa(v))
+	}
+	slices.Sort(pairs)
+	return strings.Join(pairs, "#")
 }
@@ -0,0 +1,165 @@
+// Copyright 2025 Adevinta
+
+package output
+
+import (
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/adevinta/ghe-reposec/internal/lava"
+)
+
+func TestWrite(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "reposec.json")
+
+	first := []lava.Summary{{Repository: "https://ghe.example.com/org/first.git"}}
+	if err := Write("json", file, first); err != nil {
+		t.Fatalf("unexpected error writing first output: %v", err)
+	}
+	if _, err := os.Stat(file + PrevSuffix); !os.IsNotExist(err) {
+		t.Fatalf("unexpected previous output file: %v", err)
+	}
+
+	second := []lava.Summary{{Repository: "https://ghe.example.com/org/second.git"}}
+	if err := Write("json", file, second); err != nil {
+		t.Fatalf("unexpected error writing second output: %v", err)
+	}
+
+	got, err := Read(file)
+	if err != nil {
+		t.Fatalf("unexpected error reading output: %v", err)
+	}
+	if len(got) != 1 || got[0].Repository != second[0].Repository {
+		t.Errorf("unexpected output: %+v", got)
+	}
+	prev, err := Read(file + PrevSuffix)
+	if err != nil {
+		t.Fatalf("unexpected error reading previous output: %v", err)
+	}
+	if len(prev) != 1 || prev[0].Repository != first[0].Repository {
+		t.Errorf("unexpected previous output: %+v", prev)
+	}
+	assertNoTempFiles(t, dir)
+}
+
+func TestWriteMidWriteFailure(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "reposec.json")
+
+	want := []lava.Summary{{Repository: "https://ghe.example.com/org/repo.git"}}
+	if err := Write("json", file, want); err != nil {
+		t.Fatalf("unexpected error writing output: %v", err)
+	}
+	before, err := os.ReadFile(file)
+	if err != nil {
+		t.Fatalf("unexpected error reading output: %v", err)
+	}
+
+	// A NaN score cannot be encoded as JSON, so the encoding fails after
+	// the temporary file has been created.
+	broken := []lava.Summary{
+		{Repository: "https://ghe.example.com/org/repo.git"},
+		{Repository: "https://ghe.example.com/org/broken.git", Score: math.NaN()},
+	}
+	if err := Write("json", file, broken); err == nil {
+		t.Fatal("expected error writing broken output")
+	}
+
+	after, err := os.ReadFile(file)
+	if err != nil {
+		t.Fatalf("unexpected error reading output: %v", err)
+	}
+	if string(after) != string(before) {
+		t.Errorf("output file modified after a failed write:\nbefore: %s\nafter: %s", before, after)
+	}
+	if _, err := os.Stat(file + PrevSuffix); !os.IsNotExist(err) {
+		t.Errorf("unexpected previous output file after a failed write: %v", err)
+	}
+	assertNoTempFiles(t, dir)
+}
+
+func TestWriteUnsupportedFormat(t *testing.T) {
+	dir := t.TempDir()
+	err := Write("xml", filepath.Join(dir, "reposec.xml"), nil)
+	if err != ErrUnsupportedFormat {
+		t.Fatalf("unexpected error: got %v, want %v", err, ErrUnsupportedFormat)
+	}
+	assertNoTempFiles(t, dir)
+}
+
+func TestCopyFile(t *testing.T) {
+	dir := t.TempDir()
+	src := filepath.Join(dir, "src")
+	dst := filepath.Join(dir, "dst")
+	if err := os.WriteFile(src, []byte("content"), 0644); err != nil {
+		t.Fatalf("unexpected error writing source file: %v", err)
+	}
+
+	if err := copyFile(src, dst); err != nil {
+		t.Fatalf("unexpected error copying file: %v", err)
+	}
+	got, err := os.ReadFile(dst)
+	if err != nil {
+		t.Fatalf("unexpected error reading copied file: %v", err)
+	}
+	if string(got) != "content" {
+		t.Errorf("unexpected copied content: %q", got)
+	}
+}
+
+func assertNoTempFiles(t *testing.T, dir string) {
+	t.Helper()
+
+	tmps, err := filepath.Glob(filepath.Join(dir, "*.tmp"))
+	if err != nil {
+		t.Fatalf("unexpected error listing temporary files: %v", err)
+	}
+	if len(tmps) > 0 {
+		t.Errorf("unexpected temporary files: %v", tmps)
+	}
+}
+
+func TestRunPath(t *testing.T) {
+	run := Run{ID: "20250102T150405Z-1a2b3c4d"}
+	tests := []struct {
+		file string
+		want string
+	}{
+		{file: "reposec.ndjson", want: "reposec-20250102T150405Z-1a2b3c4d.ndjson"},
+		{file: "/tmp/out.d/reposec", want: "/tmp/out.d/reposec-20250102T150405Z-1a2b3c4d"},
+	}
+	for _, tt := range tests {
+		if got := run.Path(tt.file); got != tt.want {
+			t.Errorf("unexpected path for %q: got %q, want %q", tt.file, got, tt.want)
+		}
+	}
+}
+
+func TestStreamWriter(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "reposec.ndjson")
+	if err := os.WriteFile(file, []byte(`{"repository":"https://ghe.example.com/org/old.git"}`+"\n"), 0644); err != nil {
+		t.Fatalf("unexpected error writing previous stream: %v", err)
+	}
+
+	w, err := NewStreamWriter(file)
+	if err != nil {
+		t.Fatalf("unexpected error creating stream: %v", err)
+	}
+	if err := w.Write(lava.Summary{Repository: "https://ghe.example.com/org/repo.git"}); err != nil {
+		t.Fatalf("unexpected error writing stream: %v", err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("unexpected error closing stream: %v", err)
+	}
+
+	got, err := Read(file)
+	if err != nil {
+		t.Fatalf("unexpected error reading stream: %v", err)
+	}
+	if len(got) != 1 || got[0].Repository != "https://ghe.example.com/org/repo.git" {
+		t.Errorf("unexpected stream: %+v", got)
+	}
+}
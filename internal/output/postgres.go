@@ -0,0 +1,227 @@
+// Copyright 2025 Adevinta
+
+package output
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+
+	"github.com/adevinta/ghe-reposec/internal/config"
+	"github.com/adevinta/ghe-reposec/internal/lava"
+)
+
+var (
+	// ErrPostgresDSNRequired is returned when the PostgreSQL DSN is not
+	// provided.
+	ErrPostgresDSNRequired = fmt.Errorf("PostgreSQL DSN is required and was not provided")
+
+	// ErrPostgresIdentifierTooLong is returned when a configured table name
+	// exceeds the PostgreSQL identifier length limit.
+	ErrPostgresIdentifierTooLong = fmt.Errorf("PostgreSQL identifier exceeds %d bytes", maxIdentifierLen)
+)
+
+// maxIdentifierLen is the maximum length in bytes of a PostgreSQL identifier.
+// Longer identifiers are silently truncated.
+const maxIdentifierLen = 63
+
+// migration returns the statements of a schema migration for the provided
+// raw, not sanitized, table names.
+type migration func(runs, results string) []string
+
+// migrations are the PostgreSQL schema migrations. They are applied in order
+// and must never be modified once released, only appended.
+var migrations = []migration{
+	func(runs, results string) []string {
+		return []string{
+			fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %s (
+				id          TEXT PRIMARY KEY,
+				started_at  TIMESTAMPTZ NOT NULL,
+				finished_at TIMESTAMPTZ NOT NULL,
+				config_hash TEXT NOT NULL
+			)`, pgx.Identifier{runs}.Sanitize()),
+			fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %s (
+				run_id             TEXT NOT NULL REFERENCES %s (id),
+				repository         TEXT NOT NULL,
+				organization       TEXT NOT NULL,
+				control_in_place   BOOLEAN NOT NULL,
+				number_of_controls INTEGER NOT NULL,
+				controls           TEXT[] NOT NULL,
+				error              TEXT NOT NULL,
+				summary            JSONB NOT NULL,
+				updated_at         TIMESTAMPTZ NOT NULL,
+				PRIMARY KEY (run_id, repository)
+			)`, pgx.Identifier{results}.Sanitize(), pgx.Identifier{runs}.Sanitize()),
+		}
+	},
+	func(_, results string) []string {
+		return []string{
+			fmt.Sprintf(`CREATE INDEX IF NOT EXISTS %s ON %s (organization)`,
+				pgx.Identifier{indexName(results, "organization_idx")}.Sanitize(), pgx.Identifier{results}.Sanitize()),
+			fmt.Sprintf(`CREATE INDEX IF NOT EXISTS %s ON %s (repository)`,
+				pgx.Identifier{indexName(results, "repository_idx")}.Sanitize(), pgx.Identifier{results}.Sanitize()),
+		}
+	},
+}
+
+// indexName returns the name of an index of the table. If "table_suffix"
+// exceeds the PostgreSQL identifier length limit, the table name is shortened
+// and a hash of it is added, so indexes of different tables never share the
+// same name.
+func indexName(table, suffix string) string {
+	name := table + "_" + suffix
+	if len(name) <= maxIdentifierLen {
+		return name
+	}
+	sum := sha256.Sum256([]byte(table))
+	hash := hex.EncodeToString(sum[:4])
+	return table[:maxIdentifierLen-len(hash)-len(suffix)-2] + "_" + hash + "_" + suffix
+}
+
+// PostgresWriter upserts runs and their results into PostgreSQL tables.
+type PostgresWriter struct {
+	conn       *pgx.Conn
+	cfg        config.PostgresConfig
+	runs       string
+	results    string
+	migrations string
+}
+
+// NewPostgresWriter connects to PostgreSQL and applies the pending schema
+// migrations.
+func NewPostgresWriter(ctx context.Context, cfg config.PostgresConfig) (*PostgresWriter, error) {
+	if cfg.DSN == "" {
+		return nil, ErrPostgresDSNRequired
+	}
+	for _, table := range []string{cfg.RunsTable, cfg.ResultsTable, cfg.MigrationsTable} {
+		if len(table) > maxIdentifierLen {
+			return nil, fmt.Errorf("%w: %s", ErrPostgresIdentifierTooLong, table)
+		}
+	}
+
+	conn, err := pgx.Connect(ctx, cfg.DSN)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to PostgreSQL: %w", err)
+	}
+
+	w := &PostgresWriter{
+		conn:       conn,
+		cfg:        cfg,
+		runs:       pgx.Identifier{cfg.RunsTable}.Sanitize(),
+		results:    pgx.Identifier{cfg.ResultsTable}.Sanitize(),
+		migrations: pgx.Identifier{cfg.MigrationsTable}.Sanitize(),
+	}
+	if err := w.migrate(ctx); err != nil {
+		conn.Close(ctx)
+		return nil, fmt.Errorf("failed to migrate PostgreSQL schema: %w", err)
+	}
+
+	return w, nil
+}
+
+// migrate applies the pending schema migrations. Migrations are tracked per
+// pair of runs and results tables, so changing the configured table names
+// creates the new tables.
+func (w *PostgresWriter) migrate(ctx context.Context) error {
+	tx, err := w.conn.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	_, err = tx.Exec(ctx, fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %s (
+		tables     TEXT NOT NULL,
+		version    INTEGER NOT NULL,
+		applied_at TIMESTAMPTZ NOT NULL,
+		PRIMARY KEY (tables, version)
+	)`, w.migrations))
+	if err != nil {
+		return err
+	}
+	// Serialize concurrent executions migrating the same schema.
+	_, err = tx.Exec(ctx, fmt.Sprintf("LOCK TABLE %s IN EXCLUSIVE MODE", w.migrations))
+	if err != nil {
+		return err
+	}
+
+	tables := w.cfg.RunsTable + "," + w.cfg.ResultsTable
+	var version int
+	err = tx.QueryRow(ctx, fmt.Sprintf("SELECT COALESCE(MAX(version), 0) FROM %s WHERE tables = $1", w.migrations), tables).Scan(&version)
+	if err != nil {
+		return err
+	}
+
+	for i := version; i < len(migrations); i++ {
+		for _, stmt := range migrations[i](w.cfg.RunsTable, w.cfg.ResultsTable) {
+			if _, err := tx.Exec(ctx, stmt); err != nil {
+				return fmt.Errorf("migration %d: %w", i+1, err)
+			}
+		}
+		_, err = tx.Exec(ctx, fmt.Sprintf("INSERT INTO %s (tables, version, applied_at) VALUES ($1, $2, $3)", w.migrations), tables, i+1, time.Now())
+		if err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit(ctx)
+}
+
+// Write upserts the run metadata and its summaries.
+func (w *PostgresWriter) Write(ctx context.Context, run Run, summary []lava.Summary) error {
+	tx, err := w.conn.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	now := time.Now()
+	_, err = tx.Exec(ctx, fmt.Sprintf(`INSERT INTO %s (id, started_at, finished_at, config_hash)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (id) DO UPDATE SET
+			finished_at = EXCLUDED.finished_at,
+			config_hash = EXCLUDED.config_hash`, w.runs),
+		run.ID, run.StartedAt, now, run.ConfigHash,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to upsert run: %w", err)
+	}
+
+	upsert := fmt.Sprintf(`INSERT INTO %s
+		(run_id, repository, organization, control_in_place, number_of_controls, controls, error, summary, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		ON CONFLICT (run_id, repository) DO UPDATE SET
+			organization = EXCLUDED.organization,
+			control_in_place = EXCLUDED.control_in_place,
+			number_of_controls = EXCLUDED.number_of_controls,
+			controls = EXCLUDED.controls,
+			error = EXCLUDED.error,
+			summary = EXCLUDED.summary,
+			updated_at = EXCLUDED.updated_at`, w.results)
+	batch := &pgx.Batch{}
+	for _, s := range summary {
+		data, err := json.Marshal(s)
+		if err != nil {
+			return err
+		}
+		controls := s.Controls
+		if controls == nil {
+			controls = []string{}
+		}
+		batch.Queue(upsert, run.ID, s.Repository, s.Organization(), s.ControlInPlace, s.NumberOfControls, controls, s.Error, data, now)
+	}
+	if err := tx.SendBatch(ctx, batch).Close(); err != nil {
+		return fmt.Errorf("failed to upsert results: %w", err)
+	}
+
+	return tx.Commit(ctx)
+}
+
+// Close closes the PostgreSQL connection.
+func (w *PostgresWriter) Close(ctx context.Context) error {
+	return w.conn.Close(ctx)
+}
@@ -0,0 +1,69 @@
+// Copyright 2025 Adevinta
+
+package output
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/adevinta/ghe-reposec/internal/config"
+)
+
+func TestMigrationsIdentifiers(t *testing.T) {
+	var stmts []string
+	for _, m := range migrations {
+		stmts = append(stmts, m("runs", "results")...)
+	}
+
+	want := []string{
+		`CREATE TABLE IF NOT EXISTS "runs"`,
+		`CREATE TABLE IF NOT EXISTS "results"`,
+		`REFERENCES "runs" (id)`,
+		`CREATE INDEX IF NOT EXISTS "results_organization_idx" ON "results"`,
+		`CREATE INDEX IF NOT EXISTS "results_repository_idx" ON "results"`,
+	}
+	all := strings.Join(stmts, "\n")
+	for _, w := range want {
+		if !strings.Contains(all, w) {
+			t.Errorf("missing %q in migrations:\n%s", w, all)
+		}
+	}
+	if strings.Contains(all, `\"`) || strings.Contains(all, `""`) {
+		t.Errorf("malformed identifiers in migrations:\n%s", all)
+	}
+}
+
+func TestIndexName(t *testing.T) {
+	if got, want := indexName("reposec_results", "organization_idx"), "reposec_results_organization_idx"; got != want {
+		t.Errorf("unexpected index name: got %q, want %q", got, want)
+	}
+
+	long := strings.Repeat("t", 60)
+	a := indexName(long+"_a", "organization_idx")
+	b := indexName(long+"_b", "organization_idx")
+	if a == b {
+		t.Errorf("index names of different tables collide: %q", a)
+	}
+	for _, name := range []string{a, b} {
+		if len(name) > maxIdentifierLen {
+			t.Errorf("index name exceeds %d bytes: %q", maxIdentifierLen, name)
+		}
+		if !strings.HasSuffix(name, "_organization_idx") {
+			t.Errorf("unexpected index name suffix: %q", name)
+		}
+	}
+}
+
+func TestNewPostgresWriterLongTableName(t *testing.T) {
+	_, err := NewPostgresWriter(context.Background(), config.PostgresConfig{
+		DSN:             "postgres://localhost/reposec",
+		RunsTable:       "runs",
+		ResultsTable:    strings.Repeat("t", maxIdentifierLen+1),
+		MigrationsTable: "migrations",
+	})
+	if !errors.Is(err, ErrPostgresIdentifierTooLong) {
+		t.Fatalf("unexpected error: got %v, want %v", err, ErrPostgresIdentifierTooLong)
+	}
+}
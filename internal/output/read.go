@@ -0,0 +1,180 @@
+// Copyright 2025 Adevinta
+
+package output
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/adevinta/ghe-reposec/internal/lava"
+)
+
+// Read reads a file written by [Write] or by a [StreamWriter]. The format is
+// detected from the file extension and, if unknown, from its content.
+func Read(file string) ([]lava.Summary, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+
+	format := strings.TrimPrefix(strings.ToLower(filepath.Ext(file)), ".")
+	if !supportedFormat(format) {
+		format = detectFormat(data)
+	}
+
+	switch format {
+	case "csv":
+		return readCSV(bytes.NewReader(data))
+	case "json":
+		var summary []lava.Summary
+		if err := json.Unmarshal(data, &summary); err != nil {
+			return nil, err
+		}
+		return summary, nil
+	case "ndjson":
+		return readNDJSON(bytes.NewReader(data))
+	default:
+		return nil, ErrUnsupportedFormat
+	}
+}
+
+func detectFormat(data []byte) string {
+	data = bytes.TrimSpace(data)
+	switch {
+	case bytes.HasPrefix(data, []byte("[")):
+		return "json"
+	case bytes.HasPrefix(data, []byte("{")):
+		return "ndjson"
+	default:
+		return "csv"
+	}
+}
+
+func readCSV(r io.Reader) ([]lava.Summary, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read CSV header: %w", err)
+	}
+	columns := map[string]int{}
+	for i, h := range header {
+		columns[h] = i
+	}
+	if _, ok := columns["repository"]; !ok {
+		return nil, fmt.Errorf("missing repository column in CSV header")
+	}
+	field := func(record []string, name string) string {
+		i, ok := columns[name]
+		if !ok || i >= len(record) {
+			return ""
+		}
+		return record[i]
+	}
+
+	summary := []lava.Summary{}
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		s := lava.Summary{
+			Repository:      field(record, "repository"),
+			Controls:        []string{},
+			Error:           field(record, "error"),
+			PolicyStatus:    field(record, "policy_status"),
+			MissingControls: []string{},
+			WaiverStatus:    field(record, "waiver_status"),
+			Owners:          []string{},
+			Violations:      []string{},
+			Findings:        []string{},
+		}
+		if v := field(record, "control_in_place"); v != "" {
+			if s.ControlInPlace, err = strconv.ParseBool(v); err != nil {
+				return nil, fmt.Errorf("invalid control_in_place value for %s: %w", s.Repository, err)
+			}
+		}
+		if v := field(record, "number_of_controls"); v != "" {
+			if s.NumberOfControls, err = strconv.Atoi(v); err != nil {
+				return nil, fmt.Errorf("invalid number_of_controls value for %s: %w", s.Repository, err)
+			}
+		}
+		if v := field(record, "controls"); v != "" {
+			s.Controls = strings.Split(v, "#")
+		}
+		if v := field(record, "score"); v != "" {
+			if s.Score, err = strconv.ParseFloat(v, 64); err != nil {
+				return nil, fmt.Errorf("invalid score value for %s: %w", s.Repository, err)
+			}
+		}
+		if v := field(record, "missing_controls"); v != "" {
+			s.MissingControls = strings.Split(v, "#")
+		}
+		if v := field(record, "owners"); v != "" {
+			s.Owners = strings.Split(v, "#")
+		}
+		if v := field(record, "alerts"); v != "" {
+			if s.Alerts, err = parseCounts(v); err != nil {
+				return nil, fmt.Errorf("invalid alerts value for %s: %w", s.Repository, err)
+			}
+		}
+		if v := field(record, "violations"); v != "" {
+			s.Violations = strings.Split(v, "#")
+		}
+		if v := field(record, "findings"); v != "" {
+			s.Findings = strings.Split(v, "#")
+		}
+		summary = append(summary, s)
+	}
+
+	return summary, nil
+}
+
+// parseCounts parses counts formatted by formatCounts.
+func parseCounts(v string) (map[string]int, error) {
+	counts := map[string]int{}
+	for _, pair := range strings.Split(v, "#") {
+		k, n, ok := strings.Cut(pair, "=")
+		if !ok {
+			return nil, fmt.Errorf("missing value for %q", pair)
+		}
+		i, err := strconv.Atoi(n)
+		if err != nil {
+			return nil, err
+		}
+		counts[k] = i
+	}
+	return counts, nil
+}
+
+func readNDJSON(r io.Reader) ([]lava.Summary, error) {
+	summary := []lava.Summary{}
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+		var s lava.Summary
+		if err := json.Unmarshal(line, &s); err != nil {
+			return nil, err
+		}
+		summary = append(summary, s)
+	}
+	return summary, scanner.Err()
+}
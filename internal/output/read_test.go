@@ -0,0 +1,85 @@
+// Copyright 2025 Adevinta
+
+package output
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/adevinta/ghe-reposec/internal/lava"
+)
+
+func TestReadRoundTrip(t *testing.T) {
+	want := []lava.Summary{
+		{
+			Repository:       "https://ghe.example.com/org/repo.git",
+			Controls:         []string{"sast", "codeowners"},
+			ControlInPlace:   true,
+			NumberOfControls: 2,
+			Error:            "",
+			PolicyStatus:     "non_compliant",
+			MissingControls:  []string{"sca|dependabot", "*"},
+			WaiverStatus:     "expired",
+			Score:            0.75,
+			Owners:           []string{"org/team-a", "org/team-b"},
+			Alerts:           map[string]int{"code_scanning:high": 2, "dependabot:critical": 1},
+			Violations:       []string{"open high alerts: 2 > 0"},
+			Findings:         []string{"unpinned-action:.github/workflows/ci.yml:third/action@v1"},
+		},
+		{
+			Repository:      "https://ghe.example.com/org/broken.git",
+			Controls:        []string{},
+			Error:           "scan failed",
+			MissingControls: []string{},
+			Owners:          []string{},
+			Violations:      []string{},
+			Findings:        []string{},
+		},
+	}
+
+	for _, format := range []string{"csv", "json", "ndjson"} {
+		t.Run(format, func(t *testing.T) {
+			file := filepath.Join(t.TempDir(), "reposec."+format)
+			if err := Write(format, file, want); err != nil {
+				t.Fatalf("unexpected error writing output: %v", err)
+			}
+
+			got, err := Read(file)
+			if err != nil {
+				t.Fatalf("unexpected error reading output: %v", err)
+			}
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("unexpected summary:\ngot:  %+v\nwant: %+v", got, want)
+			}
+		})
+	}
+}
+
+func TestReadDetectFormat(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+	}{
+		{name: "json", content: `[{"Repository":"https://ghe.example.com/org/repo.git"}]`},
+		{name: "ndjson", content: `{"Repository":"https://ghe.example.com/org/repo.git"}` + "\n"},
+		{name: "csv", content: "repository,controls\nhttps://ghe.example.com/org/repo.git,\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			file := filepath.Join(t.TempDir(), "reposec.out")
+			if err := os.WriteFile(file, []byte(tt.content), 0644); err != nil {
+				t.Fatalf("unexpected error writing file: %v", err)
+			}
+
+			got, err := Read(file)
+			if err != nil {
+				t.Fatalf("unexpected error reading output: %v", err)
+			}
+			if len(got) != 1 || got[0].Repository != "https://ghe.example.com/org/repo.git" {
+				t.Errorf("unexpected summary: %+v", got)
+			}
+		})
+	}
+}
@@ -0,0 +1,36 @@
+// Copyright 2025 Adevinta
+
+package output
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Run represents the metadata of a ghe-reposec execution.
+type Run struct {
+	ID         string
+	StartedAt  time.Time
+	ConfigHash string
+}
+
+// NewRun returns a new run started at the provided time.
+func NewRun(startedAt time.Time, configHash string) Run {
+	b := make([]byte, 4)
+	rand.Read(b)
+	return Run{
+		ID:         startedAt.UTC().Format("20060102T150405Z") + "-" + hex.EncodeToString(b),
+		StartedAt:  startedAt,
+		ConfigHash: configHash,
+	}
+}
+
+// Path returns the path of the file scoped to the run, which has the run ID
+// inserted before its extension.
+func (r Run) Path(file string) string {
+	ext := filepath.Ext(file)
+	return strings.TrimSuffix(file, ext) + "-" + r.ID + ext
+}
@@ -0,0 +1,50 @@
+// Copyright 2025 Adevinta
+
+package output
+
+import (
+	"encoding/json"
+	"os"
+
+	"github.com/adevinta/ghe-reposec/internal/lava"
+)
+
+// StreamWriter writes repository summaries to a NDJSON file as soon as they
+// are available.
+type StreamWriter struct {
+	f   *os.File
+	enc *json.Encoder
+}
+
+// NewStreamWriter creates a new NDJSON stream writer. The file is truncated
+// if it already exists, so it is expected to be scoped to the run with
+// [Run.Path] to keep the partial results of previous interrupted executions.
+func NewStreamWriter(file string) (*StreamWriter, error) {
+	if file == "" {
+		return nil, ErrOutputFileRequired
+	}
+
+	f, err := os.OpenFile(file, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0644)
+	if err != nil {
+		return nil, err
+	}
+
+	return &StreamWriter{
+		f:   f,
+		enc: json.NewEncoder(f),
+	}, nil
+}
+
+// Write appends a summary to the stream file and syncs it to disk, so the
+// result survives an unexpected termination of the process.
+func (w *StreamWriter) Write(s lava.Summary) error {
+	if err := w.enc.Encode(s); err != nil {
+		return err
+	}
+	return w.f.Sync()
+}
+
+// Close closes the stream file.
+func (w *StreamWriter) Close() error {
+	return w.f.Close()
+}
@@ -0,0 +1,197 @@
+// Copyright 2025 Adevinta
+
+// Package policy evaluates repository summaries against the controls required
+// for each class of repository.
+package policy
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"slices"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/adevinta/ghe-reposec/internal/github"
+	"github.com/adevinta/ghe-reposec/internal/lava"
+)
+
+// Policy status values.
+const (
+	StatusCompliant    = "compliant"
+	StatusNonCompliant = "non_compliant"
+	StatusError        = "error"
+)
+
+// AnyControl is the control name that matches any control in place.
+const AnyControl = "*"
+
+// Selector selects the repositories a rule applies to. All the non-empty
+// fields must match. Organizations support glob patterns.
+type Selector struct {
+	Organizations []string `yaml:"organizations"`
+	Topics        []string `yaml:"topics"`
+	Languages     []string `yaml:"languages"`
+	Visibilities  []string `yaml:"visibilities"`
+}
+
+// Rule defines the controls required for the repositories matching its
+// selector.
+type Rule struct {
+	Name     string   `yaml:"name"`
+	Selector Selector `yaml:"selector"`
+	// Required are the controls that must all be in place.
+	Required []string `yaml:"required"`
+	// AnyOf are the controls of which at least one must be in place.
+	AnyOf []string `yaml:"any_of"`
+}
+
+// Policy is an ordered list of rules. The first rule matching a repository
+// is applied.
+type Policy struct {
+	Rules []Rule `yaml:"rules"`
+	// Controls are the known controls. They are only used to report them,
+	// joined by "|", instead of [AnyControl] in the missing controls, and do
+	// not affect compliance.
+	Controls []string `yaml:"controls"`
+}
+
+// AddControls adds known controls, unless they are already present.
+func (p *Policy) AddControls(controls ...string) {
+	for _, c := range controls {
+		if !containsFold(p.Controls, c) {
+			p.Controls = append(p.Controls, c)
+		}
+	}
+}
+
+// DefaultRule is applied to the repositories not matching any rule. It
+// requires at least one control in place.
+var DefaultRule = Rule{
+	Name:  "default",
+	AnyOf: []string{AnyControl},
+}
+
+// Load reads a YAML policy file. If file is empty, a policy with no rules is
+// returned, so only the [DefaultRule] is applied.
+func Load(file string) (*Policy, error) {
+	if file == "" {
+		return &Policy{}, nil
+	}
+
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read policy file: %w", err)
+	}
+
+	var p Policy
+	if err := yaml.Unmarshal(data, &p); err != nil {
+		return nil, fmt.Errorf("failed to parse policy file: %w", err)
+	}
+	for i, r := range p.Rules {
+		if r.Name == "" {
+			return nil, fmt.Errorf("policy rule %d has no name", i)
+		}
+		for _, o := range r.Selector.Organizations {
+			if _, err := path.Match(o, ""); err != nil {
+				return nil, fmt.Errorf("invalid organization pattern %q in policy rule %s: %w", o, r.Name, err)
+			}
+		}
+	}
+
+	return &p, nil
+}
+
+// Rule returns the rule applied to the repository.
+func (p *Policy) Rule(repo github.Repository) Rule {
+	for _, r := range p.Rules {
+		if r.Selector.matches(repo) {
+			return r
+		}
+	}
+	return DefaultRule
+}
+
+// Evaluate sets the policy status and missing controls of the summary
+// according to the rule applied to the repository. Summaries with violations
+// are non-compliant regardless of their controls.
+func (p *Policy) Evaluate(s *lava.Summary, repo github.Repository) {
+	if s.Error != "" {
+		s.PolicyStatus = StatusError
+		return
+	}
+
+	s.MissingControls = p.Rule(repo).missing(s, p.Controls)
+	if len(s.MissingControls) > 0 || len(s.Violations) > 0 {
+		s.PolicyStatus = StatusNonCompliant
+	} else {
+		s.PolicyStatus = StatusCompliant
+	}
+}
+
+// missing returns the controls required by the rule that are not in place.
+// Unsatisfied [Rule.AnyOf] alternatives are reported joined by "|", and
+// [AnyControl] is reported as the provided known controls, if any.
+func (r Rule) missing(s *lava.Summary, known []string) []string {
+	missing := []string{}
+	for _, c := range r.Required {
+		if !hasControl(s, c) {
+			missing = append(missing, strings.Join(expand([]string{c}, known), "|"))
+		}
+	}
+	if len(r.AnyOf) > 0 && !slices.ContainsFunc(r.AnyOf, func(c string) bool { return hasControl(s, c) }) {
+		missing = append(missing, strings.Join(expand(r.AnyOf, known), "|"))
+	}
+	return missing
+}
+
+// expand replaces [AnyControl] in controls with the known controls.
+func expand(controls, known []string) []string {
+	if len(known) == 0 || !slices.Contains(controls, AnyControl) {
+		return controls
+	}
+	expanded := []string{}
+	for _, c := range controls {
+		alternatives := []string{c}
+		if c == AnyControl {
+			alternatives = known
+		}
+		for _, a := range alternatives {
+			if !containsFold(expanded, a) {
+				expanded = append(expanded, a)
+			}
+		}
+	}
+	return expanded
+}
+
+func hasControl(s *lava.Summary, control string) bool {
+	if control == AnyControl {
+		return s.ControlInPlace
+	}
+	return slices.ContainsFunc(s.Controls, func(c string) bool { return strings.EqualFold(c, control) })
+}
+
+func (sel Selector) matches(repo github.Repository) bool {
+	if len(sel.Organizations) > 0 && !slices.ContainsFunc(sel.Organizations, func(o string) bool {
+		ok, _ := path.Match(strings.ToLower(o), strings.ToLower(repo.Organization))
+		return ok
+	}) {
+		return false
+	}
+	if len(sel.Topics) > 0 && !slices.ContainsFunc(sel.Topics, func(t string) bool { return containsFold(repo.Topics, t) }) {
+		return false
+	}
+	if len(sel.Languages) > 0 && !containsFold(sel.Languages, repo.Language) {
+		return false
+	}
+	if len(sel.Visibilities) > 0 && !containsFold(sel.Visibilities, repo.Visibility) {
+		return false
+	}
+	return true
+}
+
+func containsFold(values []string, v string) bool {
+	return slices.ContainsFunc(values, func(s string) bool { return strings.EqualFold(s, v) })
+}
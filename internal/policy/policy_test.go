@@ -0,0 +1,90 @@
+// Copyright 2025 Adevinta
+
+package policy
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/adevinta/ghe-reposec/internal/github"
+	"github.com/adevinta/ghe-reposec/internal/lava"
+)
+
+func TestPolicyEvaluate(t *testing.T) {
+	tests := []struct {
+		name        string
+		policy      Policy
+		summary     lava.Summary
+		repo        github.Repository
+		wantStatus  string
+		wantMissing []string
+	}{
+		{
+			name:        "default rule with known controls",
+			policy:      Policy{Controls: []string{"sast", "sca"}},
+			summary:     lava.Summary{Repository: "https://ghe.example.com/org/repo.git"},
+			wantStatus:  StatusNonCompliant,
+			wantMissing: []string{"sast|sca"},
+		},
+		{
+			name:        "default rule without known controls",
+			summary:     lava.Summary{Repository: "https://ghe.example.com/org/repo.git"},
+			wantStatus:  StatusNonCompliant,
+			wantMissing: []string{AnyControl},
+		},
+		{
+			name:        "default rule satisfied",
+			policy:      Policy{Controls: []string{"sast"}},
+			summary:     lava.Summary{Repository: "https://ghe.example.com/org/repo.git", Controls: []string{"other"}, ControlInPlace: true},
+			wantStatus:  StatusCompliant,
+			wantMissing: []string{},
+		},
+		{
+			name: "any of with any control",
+			policy: Policy{
+				Controls: []string{"sast", "sca"},
+				Rules:    []Rule{{Name: "rule", AnyOf: []string{"secret-scanning", AnyControl, "SAST"}}},
+			},
+			summary:     lava.Summary{Repository: "https://ghe.example.com/org/repo.git"},
+			wantStatus:  StatusNonCompliant,
+			wantMissing: []string{"secret-scanning|sast|sca"},
+		},
+		{
+			name: "required controls of the matching rule",
+			policy: Policy{
+				Rules: []Rule{
+					{Name: "other", Selector: Selector{Organizations: []string{"other"}}, Required: []string{"sca"}},
+					{Name: "org", Selector: Selector{Organizations: []string{"o*"}}, Required: []string{"sast", "secret-scanning"}},
+				},
+			},
+			summary:     lava.Summary{Repository: "https://ghe.example.com/org/repo.git", Controls: []string{"SAST"}, ControlInPlace: true},
+			repo:        github.Repository{Organization: "org"},
+			wantStatus:  StatusNonCompliant,
+			wantMissing: []string{"secret-scanning"},
+		},
+		{
+			name:       "scan error",
+			summary:    lava.Summary{Repository: "https://ghe.example.com/org/repo.git", Error: "error running Lava"},
+			wantStatus: StatusError,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.policy.Evaluate(&tt.summary, tt.repo)
+			if tt.summary.PolicyStatus != tt.wantStatus {
+				t.Errorf("unexpected policy status: got %q, want %q", tt.summary.PolicyStatus, tt.wantStatus)
+			}
+			if !slices.Equal(tt.summary.MissingControls, tt.wantMissing) {
+				t.Errorf("unexpected missing controls: got %q, want %q", tt.summary.MissingControls, tt.wantMissing)
+			}
+		})
+	}
+}
+
+func TestPolicyAddControls(t *testing.T) {
+	p := Policy{Controls: []string{"sast"}}
+	p.AddControls("SAST", "sca", "sca")
+	if want := []string{"sast", "sca"}; !slices.Equal(p.Controls, want) {
+		t.Errorf("unexpected controls: got %q, want %q", p.Controls, want)
+	}
+}
@@ -0,0 +1,99 @@
+// Copyright 2025 Adevinta
+
+package policy
+
+import (
+	"math"
+	"slices"
+	"strings"
+
+	"github.com/adevinta/ghe-reposec/internal/lava"
+)
+
+// MaxScore is the score of a repository having all the weighted controls in
+// place.
+const MaxScore = 100
+
+// Scorer computes compliance scores from the controls in place.
+type Scorer struct {
+	weights map[string]float64
+	total   float64
+}
+
+// NewScorer returns a scorer using the provided weights per control. Control
+// names are case insensitive and non-positive weights are ignored.
+func NewScorer(weights map[string]float64) Scorer {
+	sc := Scorer{weights: map[string]float64{}}
+	for c, w := range weights {
+		if w <= 0 {
+			continue
+		}
+		sc.weights[strings.ToLower(c)] = w
+		sc.total += w
+	}
+	return sc
+}
+
+// Score sets the score of the summary, from 0 to [MaxScore], as the weighted
+// ratio of the controls in place. If no weights are configured, the score is
+// [MaxScore] when any control is in place and 0 otherwise.
+func (sc Scorer) Score(s *lava.Summary) {
+	if s.Error != "" {
+		s.Score = 0
+		return
+	}
+	if sc.total == 0 {
+		if s.ControlInPlace {
+			s.Score = MaxScore
+		} else {
+			s.Score = 0
+		}
+		return
+	}
+
+	seen := []string{}
+	var sum float64
+	for _, c := range s.Controls {
+		c = strings.ToLower(c)
+		if slices.Contains(seen, c) {
+			continue
+		}
+		seen = append(seen, c)
+		sum += sc.weights[c]
+	}
+	s.Score = math.Round(MaxScore*sum/sc.total*10) / 10
+}
+
+// Scores represents the aggregated compliance scores.
+type Scores struct {
+	Enterprise    float64
+	Organizations map[string]float64
+}
+
+// AggregateScores returns the average score of the successfully scanned
+// repositories per organization and for the whole enterprise.
+func AggregateScores(summary []lava.Summary) Scores {
+	sums := map[string]float64{}
+	counts := map[string]int{}
+	var sum float64
+	var count int
+	for _, s := range summary {
+		if s.Error != "" {
+			continue
+		}
+		org := s.Organization()
+		sums[org] += s.Score
+		counts[org]++
+		sum += s.Score
+		count++
+	}
+
+	scores := Scores{Organizations: map[string]float64{}}
+	for org, n := range counts {
+		scores.Organizations[org] = math.Round(sums[org]/float64(n)*10) / 10
+	}
+	if count > 0 {
+		scores.Enterprise = math.Round(sum/float64(count)*10) / 10
+	}
+	return scores
+}
@@ -0,0 +1,93 @@
+// Copyright 2025 Adevinta
+
+package policy
+
+import (
+	"maps"
+	"testing"
+
+	"github.com/adevinta/ghe-reposec/internal/lava"
+)
+
+func TestScorerScore(t *testing.T) {
+	weights := map[string]float64{"SAST": 2, "sca": 1, "secret-scanning": 1, "ignored": -1}
+	tests := []struct {
+		name    string
+		weights map[string]float64
+		summary lava.Summary
+		want    float64
+	}{
+		{
+			name:    "all controls",
+			weights: weights,
+			summary: lava.Summary{Controls: []string{"sast", "sca", "secret-scanning"}, ControlInPlace: true},
+			want:    100,
+		},
+		{
+			name:    "weighted controls",
+			weights: weights,
+			summary: lava.Summary{Controls: []string{"sast", "SAST", "sca"}, ControlInPlace: true},
+			want:    75,
+		},
+		{
+			name:    "fractional score",
+			weights: map[string]float64{"sast": 1, "sca": 1, "secret-scanning": 1},
+			summary: lava.Summary{Controls: []string{"sast"}, ControlInPlace: true},
+			want:    33.3,
+		},
+		{
+			name:    "unknown controls",
+			weights: weights,
+			summary: lava.Summary{Controls: []string{"license", "ignored"}, ControlInPlace: true},
+			want:    0,
+		},
+		{
+			name:    "failed scan",
+			weights: weights,
+			summary: lava.Summary{Controls: []string{"sast"}, ControlInPlace: true, Error: "scan failed"},
+			want:    0,
+		},
+		{
+			name:    "no weights with controls",
+			summary: lava.Summary{Controls: []string{"sast"}, ControlInPlace: true},
+			want:    MaxScore,
+		},
+		{
+			name:    "no weights without controls",
+			weights: map[string]float64{"sast": 0},
+			summary: lava.Summary{Controls: []string{"license"}},
+			want:    0,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := tt.summary
+			NewScorer(tt.weights).Score(&s)
+			if s.Score != tt.want {
+				t.Errorf("unexpected score: got %v, want %v", s.Score, tt.want)
+			}
+		})
+	}
+}
+
+func TestAggregateScores(t *testing.T) {
+	summary := []lava.Summary{
+		{Repository: "https://ghe.example.com/org-a/repo-1.git", Score: 100},
+		{Repository: "https://ghe.example.com/org-a/repo-2.git", Score: 50},
+		{Repository: "https://ghe.example.com/org-a/repo-3.git", Score: 0, Error: "scan failed"},
+		{Repository: "https://ghe.example.com/org-b/repo-1.git", Score: 33.3},
+	}
+
+	got := AggregateScores(summary)
+	if want := 61.1; got.Enterprise != want {
+		t.Errorf("unexpected enterprise score: got %v, want %v", got.Enterprise, want)
+	}
+	want := map[string]float64{"org-a": 75, "org-b": 33.3}
+	if !maps.Equal(got.Organizations, want) {
+		t.Errorf("unexpected organization scores: got %v, want %v", got.Organizations, want)
+	}
+
+	if got := AggregateScores(nil); got.Enterprise != 0 || len(got.Organizations) != 0 {
+		t.Errorf("unexpected empty scores: %+v", got)
+	}
+}
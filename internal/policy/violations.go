@@ -0,0 +1,64 @@
+// Copyright 2025 Adevinta
+
+package policy
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/adevinta/ghe-reposec/internal/github"
+)
+
+// AlertViolations returns a violation for every severity whose number of open
+// alerts, across all tools, exceeds its threshold. Alerts are keyed by
+// "tool:severity" and thresholds by severity.
+func AlertViolations(alerts map[string]int, thresholds map[string]int) []string {
+	bySeverity := map[string]int{}
+	for k, v := range alerts {
+		_, severity, _ := strings.Cut(k, ":")
+		bySeverity[strings.ToLower(severity)] += v
+	}
+
+	violations := []string{}
+	for severity, limit := range thresholds {
+		if n := bySeverity[strings.ToLower(severity)]; n > limit {
+			violations = append(violations, fmt.Sprintf("open %s alerts: %d > %d", severity, n, limit))
+		}
+	}
+	slices.Sort(violations)
+	return violations
+}
+
+// AccessThresholds are the maximum outside collaborators and deploy keys
+// with write access to a repository. Negative values disable the threshold.
+type AccessThresholds struct {
+	MaxOutsideCollaborators int
+	MaxWriteDeployKeys      int
+	MaxDeployKeyAgeDays     int
+}
+
+// AccessViolations returns a violation for every access threshold exceeded by
+// the repository. Repositories whose access could not be audited are reported
+// with a violation, so they are not considered compliant.
+func AccessViolations(repo github.Repository, t AccessThresholds, now time.Time) []string {
+	if !repo.AccessAudited {
+		return []string{"access not audited"}
+	}
+	violations := []string{}
+	if n := len(repo.OutsideCollaborators); t.MaxOutsideCollaborators >= 0 && n > t.MaxOutsideCollaborators {
+		violations = append(violations, fmt.Sprintf("outside collaborators with write access: %d > %d", n, t.MaxOutsideCollaborators))
+	}
+	if n := len(repo.DeployKeys); t.MaxWriteDeployKeys >= 0 && n > t.MaxWriteDeployKeys {
+		violations = append(violations, fmt.Sprintf("deploy keys with write access: %d > %d", n, t.MaxWriteDeployKeys))
+	}
+	if t.MaxDeployKeyAgeDays >= 0 {
+		for _, k := range repo.DeployKeys {
+			if age := k.Age(now); age > t.MaxDeployKeyAgeDays {
+				violations = append(violations, fmt.Sprintf("deploy key %d age: %d days > %d", k.ID, age, t.MaxDeployKeyAgeDays))
+			}
+		}
+	}
+	return violations
+}
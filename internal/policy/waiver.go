@@ -0,0 +1,154 @@
+// Copyright 2025 Adevinta
+
+package policy
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"slices"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/adevinta/ghe-reposec/internal/lava"
+)
+
+// StatusWaived is the policy status of the non-compliant repositories whose
+// missing controls are covered by an active waiver.
+const StatusWaived = "waived"
+
+// Waiver status values.
+const (
+	WaiverActive  = "active"
+	WaiverExpired = "expired"
+)
+
+// Waiver exempts the matching repositories from having some controls in
+// place until its expiry date.
+type Waiver struct {
+	// Repositories are glob patterns matching "organization/repository"
+	// names.
+	Repositories []string `yaml:"repositories"`
+	// Controls are the waived controls. If empty, all controls are waived.
+	Controls      []string  `yaml:"controls"`
+	Justification string    `yaml:"justification"`
+	Owner         string    `yaml:"owner"`
+	Expires       time.Time `yaml:"expires"`
+}
+
+// Waivers is a list of waivers.
+type Waivers struct {
+	Waivers []Waiver `yaml:"waivers"`
+}
+
+// LoadWaivers reads a YAML waivers file. If file is empty, no waivers are
+// returned.
+func LoadWaivers(file string) (*Waivers, error) {
+	if file == "" {
+		return &Waivers{}, nil
+	}
+
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read waivers file: %w", err)
+	}
+
+	var w Waivers
+	if err := yaml.Unmarshal(data, &w); err != nil {
+		return nil, fmt.Errorf("failed to parse waivers file: %w", err)
+	}
+	for i, wv := range w.Waivers {
+		if len(wv.Repositories) == 0 {
+			return nil, fmt.Errorf("waiver %d has no repositories", i)
+		}
+		for _, r := range wv.Repositories {
+			if _, err := path.Match(r, ""); err != nil {
+				return nil, fmt.Errorf("invalid repository pattern %q in waiver %d: %w", r, i, err)
+			}
+		}
+		if wv.Justification == "" || wv.Owner == "" {
+			return nil, fmt.Errorf("waiver %d must have a justification and an owner", i)
+		}
+		if wv.Expires.IsZero() {
+			return nil, fmt.Errorf("waiver %d has no expiry date", i)
+		}
+	}
+
+	return &w, nil
+}
+
+// Expired returns the waivers expired at the provided time.
+func (w *Waivers) Expired(now time.Time) []Waiver {
+	expired := []Waiver{}
+	for _, wv := range w.Waivers {
+		if wv.expired(now) {
+			expired = append(expired, wv)
+		}
+	}
+	return expired
+}
+
+// Apply sets the waiver status of the summary and, if an active waiver covers
+// all its missing controls, sets its policy status to [StatusWaived].
+// Violations are only covered by waivers of all controls. It returns the
+// waiver matching the repository, if any, giving precedence to active ones.
+func (w *Waivers) Apply(s *lava.Summary, now time.Time) *Waiver {
+	var match *Waiver
+	for i, wv := range w.Waivers {
+		if !wv.matches(s.FullName()) {
+			continue
+		}
+		if wv.expired(now) {
+			if match == nil {
+				match = &w.Waivers[i]
+			}
+			continue
+		}
+		match = &w.Waivers[i]
+		break
+	}
+	if match == nil {
+		return nil
+	}
+
+	if match.expired(now) {
+		s.WaiverStatus = WaiverExpired
+		return match
+	}
+
+	s.WaiverStatus = WaiverActive
+	if s.PolicyStatus == StatusNonCompliant && match.covers(s.MissingControls) && (len(s.Violations) == 0 || len(match.Controls) == 0) {
+		s.PolicyStatus = StatusWaived
+	}
+	return match
+}
+
+// expired returns whether the waiver is expired. Waivers are valid until the
+// end of their expiry date.
+func (wv Waiver) expired(now time.Time) bool {
+	return !now.Before(wv.Expires.AddDate(0, 0, 1))
+}
+
+func (wv Waiver) matches(fullName string) bool {
+	return slices.ContainsFunc(wv.Repositories, func(p string) bool {
+		ok, _ := path.Match(strings.ToLower(p), strings.ToLower(fullName))
+		return ok
+	})
+}
+
+// covers returns whether the waiver covers all the missing controls. A
+// missing "a|b" alternative is covered if any of its controls is waived.
+func (wv Waiver) covers(missing []string) bool {
+	if len(wv.Controls) == 0 {
+		return true
+	}
+	for _, m := range missing {
+		alternatives := strings.Split(m, "|")
+		if !slices.ContainsFunc(alternatives, func(c string) bool { return containsFold(wv.Controls, c) }) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,203 @@
+// Copyright 2025 Adevinta
+
+// Package remediation implements actions that help repository owners to put
+// the missing security controls in place.
+package remediation
+
+import (
+	"bytes"
+	"fmt"
+	"log/slog"
+	"os"
+	"strings"
+	"text/template"
+
+	"github.com/adevinta/ghe-reposec/internal/config"
+	"github.com/adevinta/ghe-reposec/internal/github"
+	"github.com/adevinta/ghe-reposec/internal/lava"
+	"github.com/adevinta/ghe-reposec/internal/metrics"
+	"github.com/adevinta/ghe-reposec/internal/policy"
+)
+
+// IssueMarker identifies the issues opened by ghe-reposec.
+const IssueMarker = "<!-- ghe-reposec:missing-controls -->"
+
+// DefaultIssueTemplate is the default issue body template.
+const DefaultIssueTemplate = `The latest security controls scan reported that this repository does not
+comply with the security controls policy.
+{{if .MissingControls}}
+**Missing controls:**
+{{range .MissingControls}}
+- {{control .}}
+{{- end}}
+{{end}}
+{{- if .Violations}}
+**Violations:**
+{{range .Violations}}
+- {{.}}
+{{- end}}
+{{end}}
+{{- if .Controls}}
+**Controls in place:**
+{{range .Controls}}
+- {{.}}
+{{- end}}
+{{end}}
+{{- if .GuidanceURL}}
+Please, follow the [guidance]({{.GuidanceURL}}) to put the missing controls in place.
+{{end}}
+This issue is managed automatically and will be closed once a later scan
+reports the repository as compliant.
+`
+
+// templateFuncs are the functions available to the templates.
+var templateFuncs = template.FuncMap{
+	"control": describeControl,
+}
+
+// describeControl returns a human readable description of a missing control
+// as reported by the policy.
+func describeControl(control string) string {
+	if control == policy.AnyControl {
+		return "Any security control"
+	}
+	if alternatives := strings.Split(control, "|"); len(alternatives) > 1 {
+		return "One of: " + strings.Join(alternatives, ", ")
+	}
+	return control
+}
+
+// issueData is the data available to the issue body template.
+type issueData struct {
+	Repository      string
+	FullName        string
+	Controls        []string
+	MissingControls []string
+	Violations      []string
+	GuidanceURL     string
+}
+
+// Issues opens issues in the non-compliant repositories and closes them once
+// the repositories are compliant.
+type Issues struct {
+	cfg     config.RemediationConfig
+	cli     *github.Client
+	logger  *slog.Logger
+	metrics *metrics.Client
+	tmpl    *template.Template
+}
+
+// NewIssues creates a new issues remediation.
+func NewIssues(logger *slog.Logger, m *metrics.Client, cli *github.Client, cfg config.RemediationConfig) (*Issues, error) {
+	text := DefaultIssueTemplate
+	if cfg.IssueTemplate != "" {
+		data, err := os.ReadFile(cfg.IssueTemplate)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read issue template: %w", err)
+		}
+		text = string(data)
+	}
+	tmpl, err := template.New("issue").Funcs(templateFuncs).Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse issue template: %w", err)
+	}
+	if cfg.Concurrency <= 0 {
+		cfg.Concurrency = 1
+	}
+
+	return &Issues{
+		cfg:     cfg,
+		cli:     cli,
+		logger:  logger,
+		metrics: m,
+		tmpl:    tmpl,
+	}, nil
+}
+
+// Remediate opens or updates an issue in every non-compliant repository and
+// closes the issues of the compliant and waived ones. Repositories that
+// failed to be scanned are skipped.
+func (r *Issues) Remediate(summary []lava.Summary) {
+	r.logger.Debug("remediating with issues")
+
+	results := run(summary, r.cfg.Concurrency, func(s lava.Summary) string {
+		if s.Error != "" || s.PolicyStatus == "" {
+			return ""
+		}
+		return r.remediate(s)
+	})
+	for k, v := range results {
+		r.metrics.Gauge("remediation.issues", v, []string{fmt.Sprintf("action:%s", k)})
+	}
+	r.logger.Info("issues remediation completed", "results", results)
+}
+
+func (r *Issues) issue() github.Issue {
+	return github.Issue{
+		Title:  r.cfg.IssueTitle,
+		Label:  r.cfg.IssueLabel,
+		Marker: IssueMarker,
+	}
+}
+
+func (r *Issues) remediate(s lava.Summary) string {
+	owner, repo, ok := strings.Cut(s.FullName(), "/")
+	if !ok {
+		r.logger.Error("failed to parse repository", "repository", s.Repository)
+		return "failed"
+	}
+	issue := r.issue()
+
+	if s.PolicyStatus != policy.StatusNonCompliant {
+		n, ok, err := r.cli.OpenIssue(owner, repo, issue)
+		if err != nil {
+			r.logger.Error("failed to find open issue", "repository", s.Repository, "error", err)
+			return "failed"
+		}
+		if !ok {
+			return "none"
+		}
+		comment := "The latest scan reports the repository as compliant, closing the issue."
+		if s.PolicyStatus == policy.StatusWaived {
+			comment = "The missing controls of the repository are covered by an active waiver, closing the issue."
+		}
+		if err := r.cli.CloseIssue(owner, repo, n, comment); err != nil {
+			r.logger.Error("failed to close issue", "repository", s.Repository, "error", err)
+			return "failed"
+		}
+		r.logger.Info("issue closed", "repository", s.Repository, "issue", n)
+		return "closed"
+	}
+
+	var body bytes.Buffer
+	err := r.tmpl.Execute(&body, issueData{
+		Repository:      s.Repository,
+		FullName:        s.FullName(),
+		Controls:        s.Controls,
+		MissingControls: s.MissingControls,
+		Violations:      s.Violations,
+		GuidanceURL:     r.cfg.GuidanceURL,
+	})
+	if err != nil {
+		r.logger.Error("failed to render issue template", "repository", s.Repository, "error", err)
+		return "failed"
+	}
+	issue.Body = body.String()
+
+	n, change, err := r.cli.EnsureIssue(owner, repo, issue)
+	if err != nil {
+		r.logger.Error("failed to open issue", "repository", s.Repository, "error", err)
+		return "failed"
+	}
+	switch change {
+	case github.IssueCreated:
+		r.logger.Info("issue opened", "repository", s.Repository, "issue", n)
+		return "opened"
+	case github.IssueUpdated:
+		r.logger.Info("issue updated", "repository", s.Repository, "issue", n)
+		return "updated"
+	default:
+		r.logger.Debug("issue up to date", "repository", s.Repository, "issue", n)
+		return "none"
+	}
+}
@@ -0,0 +1,129 @@
+// Copyright 2025 Adevinta
+
+package remediation
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"strings"
+
+	"github.com/adevinta/ghe-reposec/internal/config"
+	"github.com/adevinta/ghe-reposec/internal/github"
+	"github.com/adevinta/ghe-reposec/internal/lava"
+	"github.com/adevinta/ghe-reposec/internal/metrics"
+	"github.com/adevinta/ghe-reposec/internal/policy"
+)
+
+var (
+	// ErrPullRequestTemplateRequired is returned when the file to be added
+	// by the pull requests is not provided.
+	ErrPullRequestTemplateRequired = fmt.Errorf("pull request template file is required")
+)
+
+// PullRequests opens pull requests adding a baseline security file, e.g. a
+// workflow, to the repositories without controls.
+type PullRequests struct {
+	cfg     config.RemediationConfig
+	cli     *github.Client
+	logger  *slog.Logger
+	metrics *metrics.Client
+	content []byte
+}
+
+// NewPullRequests creates a new pull requests remediation.
+func NewPullRequests(logger *slog.Logger, m *metrics.Client, cli *github.Client, cfg config.RemediationConfig) (*PullRequests, error) {
+	if cfg.PRTemplateFile == "" {
+		return nil, ErrPullRequestTemplateRequired
+	}
+	content, err := os.ReadFile(cfg.PRTemplateFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read pull request template file: %w", err)
+	}
+	if cfg.Concurrency <= 0 {
+		cfg.Concurrency = 1
+	}
+
+	return &PullRequests{
+		cfg:     cfg,
+		cli:     cli,
+		logger:  logger,
+		metrics: m,
+		content: content,
+	}, nil
+}
+
+// Remediate opens a pull request in every non-compliant repository without
+// any control in place, unless there is already an open one or a previous one
+// was declined. The repositories are the ones selected for the scan, and
+// provide the default branch the pull requests are opened against.
+func (r *PullRequests) Remediate(summary []lava.Summary, repos []github.Repository) {
+	r.logger.Debug("remediating with pull requests")
+
+	branches := make(map[string]string, len(repos))
+	for _, repo := range repos {
+		branches[strings.ToLower(repo.FullName)] = repo.DefaultBranch
+	}
+	results := run(summary, r.cfg.Concurrency, func(s lava.Summary) string {
+		if s.Error != "" || s.ControlInPlace || s.PolicyStatus != policy.StatusNonCompliant {
+			return ""
+		}
+		return r.remediate(s, branches[strings.ToLower(s.FullName())])
+	})
+	for k, v := range results {
+		r.metrics.Gauge("remediation.pull_requests", v, []string{fmt.Sprintf("action:%s", k)})
+	}
+	r.logger.Info("pull requests remediation completed", "results", results)
+}
+
+func (r *PullRequests) remediate(s lava.Summary, base string) string {
+	owner, repo, ok := strings.Cut(s.FullName(), "/")
+	if !ok {
+		r.logger.Error("failed to parse repository", "repository", s.Repository)
+		return "failed"
+	}
+	if base == "" {
+		r.logger.Error("unknown repository default branch", "repository", s.Repository)
+		return "failed"
+	}
+
+	body := "This pull request adds a baseline security configuration, as the latest " +
+		"security controls scan reported that this repository does not have any " +
+		"security control in place."
+	if r.cfg.GuidanceURL != "" {
+		body += fmt.Sprintf("\n\nPlease, review the [guidance](%s) before merging it.", r.cfg.GuidanceURL)
+	}
+
+	n, created, err := r.cli.EnsureFilePullRequest(owner, repo, github.FilePullRequest{
+		Base:          base,
+		Branch:        r.cfg.PRBranch,
+		Title:         r.cfg.PRTitle,
+		Body:          body,
+		CommitMessage: r.cfg.PRTitle,
+		Path:          r.cfg.PRTargetPath,
+		Content:       r.content,
+	})
+	if errors.Is(err, github.ErrFileExists) {
+		r.logger.Debug("pull request file already exists", "repository", s.Repository, "path", r.cfg.PRTargetPath)
+		return "file_exists"
+	}
+	if errors.Is(err, github.ErrPullRequestDeclined) {
+		r.logger.Debug("pull request declined, skipping", "repository", s.Repository, "pull_request", n)
+		return "declined"
+	}
+	if errors.Is(err, github.ErrBranchModified) {
+		r.logger.Warn("pull request branch modified by others, skipping", "repository", s.Repository, "branch", r.cfg.PRBranch)
+		return "branch_modified"
+	}
+	if err != nil {
+		r.logger.Error("failed to open pull request", "repository", s.Repository, "error", err)
+		return "failed"
+	}
+	if !created {
+		r.logger.Debug("pull request already open", "repository", s.Repository, "pull_request", n)
+		return "already_open"
+	}
+	r.logger.Info("pull request opened", "repository", s.Repository, "pull_request", n)
+	return "opened"
+}
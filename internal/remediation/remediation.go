@@ -0,0 +1,36 @@
+// Copyright 2025 Adevinta
+
+package remediation
+
+import (
+	"sync"
+
+	"github.com/adevinta/ghe-reposec/internal/lava"
+)
+
+// run calls fn concurrently for every summary and returns the number of
+// summaries per returned action. Empty actions are not counted.
+func run(summary []lava.Summary, concurrency int, fn func(s lava.Summary) string) map[string]int {
+	results := map[string]int{}
+	var mu sync.Mutex
+	sem := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+	for _, s := range summary {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			action := fn(s)
+			if action == "" {
+				return
+			}
+			mu.Lock()
+			results[action]++
+			mu.Unlock()
+		}()
+	}
+	wg.Wait()
+	return results
+}
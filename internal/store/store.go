@@ -0,0 +1,298 @@
+// Copyright 2025 Adevinta
+
+// Package store provides a SQLite based store that keeps the results of every
+// ghe-reposec execution.
+package store
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"strings"
+	"time"
+
+	// Register the SQLite database/sql driver.
+	_ "modernc.org/sqlite"
+
+	"github.com/adevinta/ghe-reposec/internal/lava"
+	"github.com/adevinta/ghe-reposec/internal/output"
+)
+
+var (
+	// ErrStorePathRequired is returned when the store path is not provided.
+	ErrStorePathRequired = fmt.Errorf("store path is required and was not provided")
+	// ErrStoreNotFound is returned when opening a store that does not exist
+	// in read-only mode.
+	ErrStoreNotFound = fmt.Errorf("store not found")
+)
+
+const schema = `
+CREATE TABLE IF NOT EXISTS runs (
+	id          TEXT PRIMARY KEY,
+	started_at  TEXT NOT NULL,
+	finished_at TEXT NOT NULL,
+	config_hash TEXT NOT NULL
+);
+CREATE TABLE IF NOT EXISTS results (
+	run_id             TEXT NOT NULL REFERENCES runs(id),
+	repository         TEXT NOT NULL,
+	organization       TEXT NOT NULL,
+	control_in_place   INTEGER NOT NULL,
+	number_of_controls INTEGER NOT NULL,
+	error              TEXT NOT NULL,
+	summary            TEXT NOT NULL,
+	PRIMARY KEY (run_id, repository)
+);
+CREATE TABLE IF NOT EXISTS result_controls (
+	run_id     TEXT NOT NULL REFERENCES runs(id),
+	repository TEXT NOT NULL,
+	control    TEXT NOT NULL,
+	PRIMARY KEY (run_id, repository, control)
+);
+CREATE INDEX IF NOT EXISTS results_organization_idx ON results (organization);
+CREATE INDEX IF NOT EXISTS results_repository_idx ON results (repository);
+`
+
+// Store is a SQLite results store.
+type Store struct {
+	db *sql.DB
+}
+
+// Open opens the SQLite database at path, creating it and its schema if
+// needed.
+func Open(path string) (*Store, error) {
+	if path == "" {
+		return nil, ErrStorePathRequired
+	}
+
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open store: %w", err)
+	}
+	if _, err := db.Exec(schema); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to create store schema: %w", err)
+	}
+
+	return &Store{db: db}, nil
+}
+
+// OpenReadOnly opens the existing SQLite database at path in read-only mode.
+// It returns [ErrStoreNotFound] if the database does not exist.
+func OpenReadOnly(path string) (*Store, error) {
+	if path == "" {
+		return nil, ErrStorePathRequired
+	}
+	if _, err := os.Stat(path); err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, fmt.Errorf("%w: %s", ErrStoreNotFound, path)
+		}
+		return nil, fmt.Errorf("failed to open store: %w", err)
+	}
+
+	db, err := sql.Open("sqlite", "file:"+path+"?mode=ro")
+	if err != nil {
+		return nil, fmt.Errorf("failed to open store: %w", err)
+	}
+	if err := db.Ping(); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to open store: %w", err)
+	}
+
+	return &Store{db: db}, nil
+}
+
+// Close closes the store.
+func (s *Store) Close() error {
+	return s.db.Close()
+}
+
+// SaveRun stores a run and its summaries.
+func (s *Store) SaveRun(run output.Run, summary []lava.Summary) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	_, err = tx.Exec(
+		"INSERT INTO runs (id, started_at, finished_at, config_hash) VALUES (?, ?, ?, ?)",
+		run.ID, formatTime(run.StartedAt), formatTime(time.Now()), run.ConfigHash,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to store run: %w", err)
+	}
+
+	for _, sm := range summary {
+		data, err := json.Marshal(sm)
+		if err != nil {
+			return err
+		}
+		_, err = tx.Exec(
+			`INSERT OR REPLACE INTO results
+			(run_id, repository, organization, control_in_place, number_of_controls, error, summary)
+			VALUES (?, ?, ?, ?, ?, ?, ?)`,
+			run.ID, sm.Repository, sm.Organization(), sm.ControlInPlace, sm.NumberOfControls, sm.Error, string(data),
+		)
+		if err != nil {
+			return fmt.Errorf("failed to store result for %s: %w", sm.Repository, err)
+		}
+		for _, c := range sm.Controls {
+			_, err = tx.Exec(
+				"INSERT OR IGNORE INTO result_controls (run_id, repository, control) VALUES (?, ?, ?)",
+				run.ID, sm.Repository, c,
+			)
+			if err != nil {
+				return fmt.Errorf("failed to store control for %s: %w", sm.Repository, err)
+			}
+		}
+	}
+
+	return tx.Commit()
+}
+
+// OrganizationTrend represents the compliance of an organization in a run.
+type OrganizationTrend struct {
+	RunID           string
+	StartedAt       time.Time
+	Organization    string
+	Repositories    int
+	WithControls    int
+	WithoutControls int
+	Errors          int
+}
+
+// OrganizationTrends returns the compliance per organization for the last
+// runs. If org is not empty, only that organization is returned.
+func (s *Store) OrganizationTrends(runs int, org string) ([]OrganizationTrend, error) {
+	rows, err := s.db.Query(
+		`SELECT r.id, r.started_at, res.organization,
+			COUNT(*),
+			SUM(CASE WHEN res.error = '' AND res.control_in_place THEN 1 ELSE 0 END),
+			SUM(CASE WHEN res.error = '' AND NOT res.control_in_place THEN 1 ELSE 0 END),
+			SUM(CASE WHEN res.error != '' THEN 1 ELSE 0 END)
+		FROM results res
+		JOIN (SELECT id, started_at FROM runs ORDER BY started_at DESC LIMIT ?) r ON r.id = res.run_id
+		WHERE ? = '' OR res.organization = ?
+		GROUP BY r.id, r.started_at, res.organization
+		ORDER BY res.organization, r.started_at`,
+		runs, org, org,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	trends := []OrganizationTrend{}
+	for rows.Next() {
+		var t OrganizationTrend
+		var startedAt string
+		if err := rows.Scan(&t.RunID, &startedAt, &t.Organization, &t.Repositories, &t.WithControls, &t.WithoutControls, &t.Errors); err != nil {
+			return nil, err
+		}
+		if t.StartedAt, err = parseTime(startedAt); err != nil {
+			return nil, err
+		}
+		trends = append(trends, t)
+	}
+
+	return trends, rows.Err()
+}
+
+// ControlTrend represents the number of repositories with a control in a run.
+type ControlTrend struct {
+	RunID        string
+	StartedAt    time.Time
+	Control      string
+	Repositories int
+}
+
+// ControlTrends returns the number of repositories per control for the last
+// runs. If control is not empty, only that control is returned.
+func (s *Store) ControlTrends(runs int, control string) ([]ControlTrend, error) {
+	rows, err := s.db.Query(
+		`SELECT r.id, r.started_at, rc.control, COUNT(*)
+		FROM result_controls rc
+		JOIN (SELECT id, started_at FROM runs ORDER BY started_at DESC LIMIT ?) r ON r.id = rc.run_id
+		WHERE ? = '' OR rc.control = ?
+		GROUP BY r.id, r.started_at, rc.control
+		ORDER BY rc.control, r.started_at`,
+		runs, control, control,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	trends := []ControlTrend{}
+	for rows.Next() {
+		var t ControlTrend
+		var startedAt string
+		if err := rows.Scan(&t.RunID, &startedAt, &t.Control, &t.Repositories); err != nil {
+			return nil, err
+		}
+		if t.StartedAt, err = parseTime(startedAt); err != nil {
+			return nil, err
+		}
+		trends = append(trends, t)
+	}
+
+	return trends, rows.Err()
+}
+
+// RepositoryTrend represents the result of a repository in a run.
+type RepositoryTrend struct {
+	RunID     string
+	StartedAt time.Time
+	Summary   lava.Summary
+}
+
+// RepositoryTrends returns the results of the repositories containing the
+// provided pattern for the last runs.
+func (s *Store) RepositoryTrends(runs int, pattern string) ([]RepositoryTrend, error) {
+	rows, err := s.db.Query(
+		`SELECT r.id, r.started_at, res.summary
+		FROM results res
+		JOIN (SELECT id, started_at FROM runs ORDER BY started_at DESC LIMIT ?) r ON r.id = res.run_id
+		WHERE res.repository LIKE ? ESCAPE '\'
+		ORDER BY res.repository, r.started_at`,
+		runs, "%"+escapeLike(pattern)+"%",
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	trends := []RepositoryTrend{}
+	for rows.Next() {
+		var t RepositoryTrend
+		var startedAt, data string
+		if err := rows.Scan(&t.RunID, &startedAt, &data); err != nil {
+			return nil, err
+		}
+		if t.StartedAt, err = parseTime(startedAt); err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal([]byte(data), &t.Summary); err != nil {
+			return nil, err
+		}
+		trends = append(trends, t)
+	}
+
+	return trends, rows.Err()
+}
+
+func formatTime(t time.Time) string {
+	return t.UTC().Format(time.RFC3339)
+}
+
+func parseTime(s string) (time.Time, error) {
+	return time.Parse(time.RFC3339, s)
+}
+
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(s)
+}
@@ -0,0 +1,133 @@
+// Copyright 2025 Adevinta
+
+package store
+
+import (
+	"errors"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/adevinta/ghe-reposec/internal/lava"
+	"github.com/adevinta/ghe-reposec/internal/output"
+)
+
+func TestStore(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "reposec.db")
+	st, err := Open(path)
+	if err != nil {
+		t.Fatalf("unexpected error opening store: %v", err)
+	}
+	defer st.Close()
+
+	first := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	second := first.Add(24 * time.Hour)
+	runs := []struct {
+		run     output.Run
+		summary []lava.Summary
+	}{
+		// The second run is saved first to check the ordering.
+		{
+			run: output.Run{ID: "second", StartedAt: second},
+			summary: []lava.Summary{
+				{Repository: "https://ghe.example.com/org/repo.git", Controls: []string{"sast", "sca"}, ControlInPlace: true, NumberOfControls: 2},
+				{Repository: "https://ghe.example.com/org/other.git", Error: "scan failed"},
+			},
+		},
+		{
+			run: output.Run{ID: "first", StartedAt: first},
+			summary: []lava.Summary{
+				{Repository: "https://ghe.example.com/org/repo.git", Controls: []string{"sast"}, ControlInPlace: true, NumberOfControls: 1},
+				{Repository: "https://ghe.example.com/org/other.git", Controls: []string{}},
+			},
+		},
+	}
+	for _, r := range runs {
+		if err := st.SaveRun(r.run, r.summary); err != nil {
+			t.Fatalf("unexpected error saving run %s: %v", r.run.ID, err)
+		}
+	}
+
+	orgs, err := st.OrganizationTrends(10, "")
+	if err != nil {
+		t.Fatalf("unexpected error querying organization trends: %v", err)
+	}
+	wantOrgs := []OrganizationTrend{
+		{RunID: "first", StartedAt: first, Organization: "org", Repositories: 2, WithControls: 1, WithoutControls: 1},
+		{RunID: "second", StartedAt: second, Organization: "org", Repositories: 2, WithControls: 1, Errors: 1},
+	}
+	if !slices.Equal(orgs, wantOrgs) {
+		t.Errorf("unexpected organization trends:\ngot:  %+v\nwant: %+v", orgs, wantOrgs)
+	}
+
+	orgs, err = st.OrganizationTrends(1, "")
+	if err != nil {
+		t.Fatalf("unexpected error querying organization trends: %v", err)
+	}
+	if len(orgs) != 1 || orgs[0].RunID != "second" {
+		t.Errorf("unexpected last organization trends: %+v", orgs)
+	}
+
+	controls, err := st.ControlTrends(10, "sast")
+	if err != nil {
+		t.Fatalf("unexpected error querying control trends: %v", err)
+	}
+	wantControls := []ControlTrend{
+		{RunID: "first", StartedAt: first, Control: "sast", Repositories: 1},
+		{RunID: "second", StartedAt: second, Control: "sast", Repositories: 1},
+	}
+	if !slices.Equal(controls, wantControls) {
+		t.Errorf("unexpected control trends:\ngot:  %+v\nwant: %+v", controls, wantControls)
+	}
+
+	repos, err := st.RepositoryTrends(10, "org/repo")
+	if err != nil {
+		t.Fatalf("unexpected error querying repository trends: %v", err)
+	}
+	if len(repos) != 2 {
+		t.Fatalf("unexpected repository trends: %+v", repos)
+	}
+	for i, want := range []string{"first", "second"} {
+		if repos[i].RunID != want || repos[i].Summary.Repository != "https://ghe.example.com/org/repo.git" {
+			t.Errorf("unexpected repository trend %d: %+v", i, repos[i])
+		}
+	}
+	if got := repos[1].Summary.Controls; !slices.Equal(got, []string{"sast", "sca"}) {
+		t.Errorf("unexpected repository controls: %v", got)
+	}
+}
+
+func TestOpenReadOnly(t *testing.T) {
+	dir := t.TempDir()
+
+	if _, err := OpenReadOnly(filepath.Join(dir, "missing.db")); !errors.Is(err, ErrStoreNotFound) {
+		t.Fatalf("unexpected error opening missing store: got %v, want %v", err, ErrStoreNotFound)
+	}
+
+	path := filepath.Join(dir, "reposec.db")
+	st, err := Open(path)
+	if err != nil {
+		t.Fatalf("unexpected error opening store: %v", err)
+	}
+	if err := st.SaveRun(output.Run{ID: "run", StartedAt: time.Now()}, []lava.Summary{{Repository: "https://ghe.example.com/org/repo.git"}}); err != nil {
+		t.Fatalf("unexpected error saving run: %v", err)
+	}
+	st.Close()
+
+	ro, err := OpenReadOnly(path)
+	if err != nil {
+		t.Fatalf("unexpected error opening store read-only: %v", err)
+	}
+	defer ro.Close()
+	trends, err := ro.OrganizationTrends(10, "org")
+	if err != nil {
+		t.Fatalf("unexpected error querying organization trends: %v", err)
+	}
+	if len(trends) != 1 || trends[0].Repositories != 1 {
+		t.Errorf("unexpected organization trends: %+v", trends)
+	}
+	if err := ro.SaveRun(output.Run{ID: "other", StartedAt: time.Now()}, nil); err == nil {
+		t.Error("expected error writing to a read-only store")
+	}
+}
@@ -0,0 +1,66 @@
+// Copyright 2025 Adevinta
+
+// Package tracing provides OpenTelemetry tracing for ghe-reposec.
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/adevinta/ghe-reposec/internal/config"
+)
+
+const tracerName = "github.com/adevinta/ghe-reposec"
+
+// Tracer returns the ghe-reposec tracer. It is a no-op tracer unless tracing
+// has been enabled with [Setup].
+func Tracer() trace.Tracer {
+	return otel.Tracer(tracerName)
+}
+
+// Setup configures the global tracer provider to export spans to the
+// configured OTLP/HTTP endpoint. The returned function flushes the pending
+// spans and must be called before exiting.
+func Setup(ctx context.Context, logger *slog.Logger, cfg config.TracingConfig) (func(context.Context) error, error) {
+	if !cfg.Enabled {
+		logger.Info("tracing disabled")
+		return func(context.Context) error { return nil }, nil
+	}
+
+	opts := []otlptracehttp.Option{}
+	if cfg.Endpoint != "" {
+		opts = append(opts, otlptracehttp.WithEndpoint(cfg.Endpoint))
+	}
+	if cfg.Insecure {
+		opts = append(opts, otlptracehttp.WithInsecure())
+	}
+	exporter, err := otlptracehttp.New(ctx, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create OTLP trace exporter: %w", err)
+	}
+
+	res, err := resource.Merge(
+		resource.Default(),
+		resource.NewSchemaless(attribute.String("service.name", cfg.ServiceName)),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create tracing resource: %w", err)
+	}
+
+	tp := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+	)
+	otel.SetTracerProvider(tp)
+	logger.Info("tracing enabled", "endpoint", cfg.Endpoint)
+
+	return tp.Shutdown, nil
+}
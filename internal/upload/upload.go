@@ -0,0 +1,106 @@
+// Copyright 2025 Adevinta
+
+// Package upload provides a client to upload ghe-reposec reports and Lava
+// results to S3 compatible object storages.
+package upload
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path"
+	"path/filepath"
+	"time"
+
+	"github.com/minio/minio-go/v7"
+	"github.com/minio/minio-go/v7/pkg/credentials"
+
+	"github.com/adevinta/ghe-reposec/internal/config"
+)
+
+var (
+	// ErrBucketRequired is returned when the S3 bucket is not provided.
+	ErrBucketRequired = fmt.Errorf("S3 bucket is required")
+)
+
+// Client is a S3 compatible object storage client wrapper.
+type Client struct {
+	cfg    config.S3Config
+	client *minio.Client
+	logger *slog.Logger
+	ctx    context.Context
+}
+
+// NewClient creates a new S3 compatible object storage client. Static
+// credentials are used if provided, otherwise they are obtained from the
+// environment, the AWS credentials file or the IAM role.
+func NewClient(ctx context.Context, logger *slog.Logger, cfg config.S3Config) (*Client, error) {
+	if cfg.Bucket == "" {
+		return nil, ErrBucketRequired
+	}
+
+	creds := credentials.NewChainCredentials([]credentials.Provider{
+		&credentials.EnvAWS{},
+		&credentials.FileAWSCredentials{},
+		&credentials.IAM{},
+	})
+	if cfg.AccessKeyID != "" {
+		creds = credentials.NewStaticV4(cfg.AccessKeyID, cfg.SecretAccessKey, "")
+	}
+
+	client, err := minio.New(cfg.Endpoint, &minio.Options{
+		Creds:  creds,
+		Secure: cfg.UseSSL,
+		Region: cfg.Region,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create S3 client: %w", err)
+	}
+
+	return &Client{
+		cfg:    cfg,
+		client: client,
+		logger: logger,
+		ctx:    ctx,
+	}, nil
+}
+
+// Prefix returns the key prefix for the objects of a run, based on the run
+// date and ID.
+func (c *Client) Prefix(runID string, startedAt time.Time) string {
+	return path.Join(c.cfg.Prefix, startedAt.UTC().Format(time.DateOnly), runID)
+}
+
+// UploadFile uploads a local file to the provided key.
+func (c *Client) UploadFile(file, key string) error {
+	_, err := c.client.FPutObject(c.ctx, c.cfg.Bucket, key, file, minio.PutObjectOptions{})
+	if err != nil {
+		return fmt.Errorf("failed to upload %s: %w", file, err)
+	}
+	c.logger.Debug("file uploaded", "file", file, "bucket", c.cfg.Bucket, "key", key)
+	return nil
+}
+
+// UploadFiles uploads the provided files under the root directory, keeping
+// their paths relative to root under the provided key prefix. Files that do
+// not exist are skipped.
+func (c *Client) UploadFiles(root string, files []string, prefix string) error {
+	for _, f := range files {
+		if _, err := os.Stat(f); errors.Is(err, fs.ErrNotExist) {
+			c.logger.Debug("file not found, skipping upload", "file", f)
+			continue
+		}
+		rel, err := filepath.Rel(root, f)
+		if err != nil {
+			return err
+		}
+		if err := c.UploadFile(f, path.Join(prefix, filepath.ToSlash(rel))); err != nil {
+			return err
+		}
+	}
+	c.logger.Debug("files uploaded", "path", root, "bucket", c.cfg.Bucket, "prefix", prefix, "files", len(files))
+	return nil
+}
@@ -0,0 +1,113 @@
+// Copyright 2025 Adevinta
+
+package upload
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"maps"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/adevinta/ghe-reposec/internal/config"
+)
+
+// newTestClient returns a client for a S3 stand-in and a function returning
+// the objects uploaded to it, keyed by "bucket/key".
+func newTestClient(t *testing.T) (*Client, func() map[string][]byte) {
+	t.Helper()
+
+	var (
+		mu      sync.Mutex
+		objects = map[string][]byte{}
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPut {
+			w.WriteHeader(http.StatusNotImplemented)
+			return
+		}
+		data, err := io.ReadAll(r.Body)
+		if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		mu.Lock()
+		objects[strings.TrimPrefix(r.URL.Path, "/")] = data
+		mu.Unlock()
+		w.Header().Set("ETag", `"etag"`)
+	}))
+	t.Cleanup(srv.Close)
+
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	cli, err := NewClient(context.Background(), logger, config.S3Config{
+		Endpoint:        strings.TrimPrefix(srv.URL, "http://"),
+		Region:          "us-east-1",
+		Bucket:          "bucket",
+		Prefix:          "reposec",
+		AccessKeyID:     "key",
+		SecretAccessKey: "secret",
+	})
+	if err != nil {
+		t.Fatalf("unexpected error creating client: %v", err)
+	}
+	return cli, func() map[string][]byte {
+		mu.Lock()
+		defer mu.Unlock()
+		return objects
+	}
+}
+
+func TestUpload(t *testing.T) {
+	cli, objects := newTestClient(t)
+
+	dir := t.TempDir()
+	output := filepath.Join(dir, "reposec.csv")
+	results := filepath.Join(dir, "results")
+	files := map[string]string{
+		output: "repository\n",
+		filepath.Join(results, "org", "repo.stdout"): "stdout",
+	}
+	for f, content := range files {
+		if err := os.MkdirAll(filepath.Dir(f), 0755); err != nil {
+			t.Fatalf("unexpected error creating directory: %v", err)
+		}
+		if err := os.WriteFile(f, []byte(content), 0644); err != nil {
+			t.Fatalf("unexpected error writing file: %v", err)
+		}
+	}
+
+	prefix := cli.Prefix("20250102T150405Z-1a2b3c4d", time.Date(2025, 1, 2, 15, 4, 5, 0, time.UTC))
+	if want := "reposec/2025-01-02/20250102T150405Z-1a2b3c4d"; prefix != want {
+		t.Errorf("unexpected prefix: got %q, want %q", prefix, want)
+	}
+	if err := cli.UploadFile(output, path.Join(prefix, "reposec.csv")); err != nil {
+		t.Fatalf("unexpected error uploading file: %v", err)
+	}
+	resultFiles := []string{
+		filepath.Join(results, "org", "repo.stdout"),
+		filepath.Join(results, "org", "repo.stderr"),
+	}
+	if err := cli.UploadFiles(results, resultFiles, path.Join(prefix, "results")); err != nil {
+		t.Fatalf("unexpected error uploading files: %v", err)
+	}
+
+	// The content is not compared, as it is sent with chunk signatures
+	// over plain HTTP.
+	got := slices.Sorted(maps.Keys(objects()))
+	want := []string{
+		"bucket/reposec/2025-01-02/20250102T150405Z-1a2b3c4d/reposec.csv",
+		"bucket/reposec/2025-01-02/20250102T150405Z-1a2b3c4d/results/org/repo.stdout",
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("unexpected objects:\ngot:  %q\nwant: %q", got, want)
+	}
+}
@@ -7,35 +7,91 @@ package main
 import (
 	"context"
 	"fmt"
+	"log/slog"
 	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
 	"time"
 
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+
 	"github.com/adevinta/ghe-reposec/internal/config"
 	"github.com/adevinta/ghe-reposec/internal/github"
 	"github.com/adevinta/ghe-reposec/internal/lava"
 	"github.com/adevinta/ghe-reposec/internal/metrics"
+	"github.com/adevinta/ghe-reposec/internal/notify"
 	"github.com/adevinta/ghe-reposec/internal/output"
+	"github.com/adevinta/ghe-reposec/internal/policy"
+	"github.com/adevinta/ghe-reposec/internal/remediation"
+	"github.com/adevinta/ghe-reposec/internal/store"
+	"github.com/adevinta/ghe-reposec/internal/tracing"
+	"github.com/adevinta/ghe-reposec/internal/upload"
 )
 
 func main() {
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "diff":
+			os.Exit(runDiff(os.Args[2:]))
+		case "history":
+			os.Exit(runHistory(os.Args[2:]))
+		}
+	}
+
+	os.Exit(runScan())
+}
+
+// runScan scans the GitHub Enterprise repositories and reports the results.
+// It returns the process exit code, so the deferred functions flushing the
+// metrics, the stream and the traces run before exiting, also on failures.
+func runScan() (code int) {
 	st := time.Now()
 
 	cfg, err := config.Load()
 	if err != nil {
 		fmt.Printf("failed to load configuration: %v\n", err)
-		os.Exit(1)
+		return 1
 	}
+	run := output.NewRun(st, cfg.Hash())
 
 	logger := cfg.NewLogger()
-	logger.Info("starting GitHub Enterprise reposec")
+	logger.Info("starting GitHub Enterprise reposec", "run", run.ID)
 	logger.Info("configuration", "config", cfg.Redacted())
 
 	ctx := context.Background()
 
+	shutdownTracing, err := tracing.Setup(ctx, &logger, cfg.TracingCfg)
+	if err != nil {
+		logger.Error("failed to set up tracing", "error", err)
+		return 1
+	}
+	defer func() {
+		if err := shutdownTracing(context.Background()); err != nil {
+			logger.Error("failed to shut down tracing", "error", err)
+		}
+	}()
+
+	ctx, span := tracing.Tracer().Start(ctx, "reposec.run",
+		trace.WithAttributes(
+			attribute.String("run.id", run.ID),
+			attribute.String("target_org", cfg.TargetOrg),
+		),
+	)
+	defer func() {
+		if code != 0 {
+			span.SetStatus(codes.Error, "reposec run failed")
+		}
+		span.End()
+	}()
+
 	metrics, err := metrics.NewClient(ctx, &logger, cfg.MetricsCfg)
 	if err != nil {
 		logger.Error("failed to create metrics client", "error", err)
-		os.Exit(1)
+		return 1
 	}
 	defer func() {
 		metrics.Flush()
@@ -46,60 +102,364 @@ func main() {
 	if err != nil {
 		logger.Error("failed to create GitHub client", "error", err)
 		metrics.ServiceCheck(2, err.Error(), []string{""})
-		os.Exit(1)
+		return 1
 	}
 
-	lava, err := lava.NewClient(ctx, &logger, cfg.LavaCfg)
+	lavaCli, err := lava.NewClient(ctx, &logger, metrics, cfg.LavaCfg)
 	if err != nil {
 		logger.Error("failed to create Lava client", "error", err)
 		metrics.ServiceCheck(2, err.Error(), []string{""})
-		os.Exit(1)
+		return 1
+	}
+
+	pol, err := policy.Load(cfg.PolicyFilePath)
+	if err != nil {
+		logger.Error("failed to load policy", "error", err)
+		metrics.ServiceCheck(2, err.Error(), []string{""})
+		return 1
+	}
+	pol.AddControls(cfg.ComplianceControls...)
+	if len(pol.Controls) == 0 {
+		logger.Warn("no known controls, missing controls satisfied by any control are reported as *")
+	}
+
+	waivers, err := policy.LoadWaivers(cfg.WaiversFilePath)
+	if err != nil {
+		logger.Error("failed to load waivers", "error", err)
+		metrics.ServiceCheck(2, err.Error(), []string{""})
+		return 1
+	}
+	expiredWaivers := waivers.Expired(st)
+	for _, w := range expiredWaivers {
+		logger.Error("expired waiver", "repositories", w.Repositories, "controls", w.Controls, "owner", w.Owner, "justification", w.Justification, "expires", w.Expires.Format(time.DateOnly))
+	}
+	metrics.Gauge("waivers.expired", len(expiredWaivers), []string{})
+
+	scorer := policy.NewScorer(cfg.ScoreWeights)
+
+	var issues *remediation.Issues
+	if cfg.RemediationCfg.Issues {
+		issues, err = remediation.NewIssues(&logger, metrics, cli, cfg.RemediationCfg)
+		if err != nil {
+			logger.Error("failed to create issues remediation", "error", err)
+			metrics.ServiceCheck(2, err.Error(), []string{""})
+			return 1
+		}
+	}
+	var pulls *remediation.PullRequests
+	if cfg.RemediationCfg.PullRequests {
+		pulls, err = remediation.NewPullRequests(&logger, metrics, cli, cfg.RemediationCfg)
+		if err != nil {
+			logger.Error("failed to create pull requests remediation", "error", err)
+			metrics.ServiceCheck(2, err.Error(), []string{""})
+			return 1
+		}
+	}
+
+	var slack *notify.Slack
+	if cfg.NotifyCfg.SlackWebhooksFile != "" {
+		slack, err = notify.NewSlack(&logger, metrics, cfg.NotifyCfg)
+		if err != nil {
+			logger.Error("failed to create Slack notifier", "error", err)
+			metrics.ServiceCheck(2, err.Error(), []string{""})
+			return 1
+		}
+	}
+	var email *notify.Email
+	if cfg.NotifyCfg.EmailRecipientsFile != "" {
+		email, err = notify.NewEmail(&logger, metrics, cfg.NotifyCfg)
+		if err != nil {
+			logger.Error("failed to create email notifier", "error", err)
+			metrics.ServiceCheck(2, err.Error(), []string{""})
+			return 1
+		}
+	}
+	collectors := []github.Collector{}
+	if cfg.OwnersCfg.Enabled || slack != nil || email != nil {
+		owners, err := github.NewOwnerResolver(cli, cfg.OwnersCfg)
+		if err != nil {
+			logger.Error("failed to create owner resolver", "error", err)
+			metrics.ServiceCheck(2, err.Error(), []string{""})
+			return 1
+		}
+		collectors = append(collectors, owners)
+	}
+	if cfg.CollectorsCfg.SecurityAnalysis {
+		collectors = append(collectors, github.NewSecurityAnalysis(cli))
+	}
+	if cfg.CollectorsCfg.BranchProtection {
+		collectors = append(collectors, github.NewBranchProtection(cli))
+	}
+	if cfg.CollectorsCfg.Alerts {
+		collectors = append(collectors, github.NewAlerts(cli))
+	}
+	if cfg.CollectorsCfg.Workflows {
+		collectors = append(collectors, github.NewWorkflows(cli))
+	}
+	if cfg.CollectorsCfg.Access {
+		collectors = append(collectors, github.NewAccess(cli))
+	}
+	var webhooks *github.Webhooks
+	if cfg.CollectorsCfg.Webhooks {
+		webhooks = github.NewWebhooks(cli, cfg.CollectorsCfg.WebhooksAllowedDomains)
+		collectors = append(collectors, webhooks)
+	}
+	if cfg.CollectorsCfg.Files {
+		collectors = append(collectors, github.NewFiles(cli))
+	}
+	accessThresholds := policy.AccessThresholds{
+		MaxOutsideCollaborators: cfg.CollectorsCfg.MaxOutsideCollaborators,
+		MaxWriteDeployKeys:      cfg.CollectorsCfg.MaxWriteDeployKeys,
+		MaxDeployKeyAgeDays:     cfg.CollectorsCfg.MaxDeployKeyAgeDays,
 	}
 
 	repos, err := cli.Repositories(cfg.TargetOrg)
 	if err != nil {
 		logger.Error("failed to fetch repositories", "error", err)
 		metrics.ServiceCheck(2, err.Error(), []string{""})
-		os.Exit(1)
+		return 1
 	}
 	logger.Info("repositories selected", "count", len(repos), "duration", time.Since(st).Seconds())
 
-	summary := lava.Scan(repos)
-	pushSummaryMetrics(metrics, summary)
+	if len(collectors) > 0 {
+		cli.Collect(repos, collectors...)
+		logger.Info("repository data collected", "collectors", len(collectors), "duration", time.Since(st).Seconds())
+	}
+
+	targets := make([]string, 0, len(repos))
+	repoIndex := make(map[string]github.Repository, len(repos))
+	for _, r := range repos {
+		targets = append(targets, r.CloneURL)
+		repoIndex[strings.ToLower(r.FullName)] = r
+	}
+	lookupRepo := func(s *lava.Summary) github.Repository {
+		if r, ok := repoIndex[strings.ToLower(s.FullName())]; ok {
+			return r
+		}
+		return github.Repository{Organization: s.Organization(), FullName: s.FullName()}
+	}
+
+	handlers := []lava.Handler{
+		func(s *lava.Summary) {
+			repo := lookupRepo(s)
+			s.Owners = repo.Owners
+			if s.Owners == nil {
+				s.Owners = []string{}
+			}
+			// The controls of the repositories that failed to be scanned
+			// are unknown, so the collected ones are not added.
+			if s.Error == "" {
+				for _, c := range repo.Controls {
+					s.AddControl(c, slices.ContainsFunc(cfg.ComplianceControls, func(cc string) bool { return strings.EqualFold(cc, c) }))
+				}
+			}
+			s.Alerts = repo.Alerts
+			s.Findings = repo.Findings
+			s.Violations = policy.AlertViolations(repo.Alerts, cfg.CollectorsCfg.AlertThresholds)
+			if cfg.CollectorsCfg.Access {
+				s.Violations = append(s.Violations, policy.AccessViolations(repo, accessThresholds, st)...)
+			}
+			pol.Evaluate(s, repo)
+			if w := waivers.Apply(s, st); w != nil && s.WaiverStatus == policy.WaiverExpired {
+				logger.Error("repository waiver expired", "repository", s.Repository, "owner", w.Owner, "expires", w.Expires.Format(time.DateOnly), "policy_status", s.PolicyStatus)
+			}
+			scorer.Score(s)
+		},
+	}
+	var stream *output.StreamWriter
+	streamFile := run.Path(cfg.OutputStreamFilePath)
+	if cfg.OutputStreamFilePath != "" {
+		stream, err = output.NewStreamWriter(streamFile)
+		if err != nil {
+			logger.Error("failed to create output stream", "error", err)
+			metrics.ServiceCheck(2, err.Error(), []string{""})
+			return 1
+		}
+		handlers = append(handlers, func(s *lava.Summary) {
+			if err := stream.Write(*s); err != nil {
+				logger.Error("failed to write output stream", "repository", s.Repository, "error", err)
+			}
+		})
+		logger.Info("streaming output", "file", streamFile)
+	}
+
+	summary := lavaCli.Scan(targets, handlers...)
+	if stream != nil {
+		// The stream is closed as soon as the scan completes, so it is
+		// complete before being uploaded.
+		if err := stream.Close(); err != nil {
+			logger.Error("failed to close output stream", "error", err)
+		}
+	}
+	pushSummaryMetrics(metrics, summary, cfg.MetricsCfg.ControlsByOrganization)
+	pushScoreMetrics(&logger, metrics, policy.AggregateScores(summary))
+	if cfg.CollectorsCfg.Alerts {
+		pushAlertMetrics(metrics, summary)
+	}
+	if cfg.CollectorsCfg.Workflows || cfg.CollectorsCfg.Access || cfg.CollectorsCfg.Webhooks || cfg.CollectorsCfg.Files {
+		pushFindingMetrics(metrics, summary)
+	}
+	if webhooks != nil {
+		pushOrganizationFindings(&logger, metrics, webhooks.Organizations())
+	}
 
 	err = output.Write(cfg.OutputFormat, cfg.OutputFilePath, summary)
 	if err != nil {
 		logger.Error("failed to write output", "error", err)
 		metrics.ServiceCheck(2, err.Error(), []string{""})
-		os.Exit(1)
+		return 1
 	}
 	logger.Info("output written", "file", cfg.OutputFilePath)
 
+	if cfg.StorePath != "" {
+		err = storeRun(cfg.StorePath, run, summary)
+		if err != nil {
+			logger.Error("failed to store run results", "error", err)
+			metrics.ServiceCheck(2, err.Error(), []string{""})
+			return 1
+		}
+		logger.Info("run results stored", "store", cfg.StorePath, "run", run.ID)
+	}
+
+	if cfg.PostgresCfg.DSN != "" {
+		err = writePostgres(ctx, cfg.PostgresCfg, run, summary)
+		if err != nil {
+			logger.Error("failed to write PostgreSQL output", "error", err)
+			metrics.ServiceCheck(2, err.Error(), []string{""})
+			return 1
+		}
+		logger.Info("PostgreSQL output written", "table", cfg.PostgresCfg.ResultsTable, "run", run.ID)
+	}
+
+	if cfg.S3Cfg.Bucket != "" {
+		err = uploadResults(ctx, &logger, cfg, run, lavaCli.ResultFiles())
+		if err != nil {
+			logger.Error("failed to upload results", "error", err)
+			metrics.ServiceCheck(2, err.Error(), []string{""})
+			return 1
+		}
+		logger.Info("results uploaded", "bucket", cfg.S3Cfg.Bucket)
+	}
+
+	if issues != nil {
+		issues.Remediate(summary)
+	}
+	if pulls != nil {
+		pulls.Remediate(summary, repos)
+	}
+
+	if slack != nil {
+		slack.Notify(summary)
+	}
+	if email != nil {
+		email.Notify(summary)
+	}
+
 	metrics.Gauge("took", int(time.Since(st).Seconds()), []string{})
 	metrics.ServiceCheck(0, "OK", []string{""})
 
 	logger.Info("GitHub Enterprise reposec completed", "duration", time.Since(st).Seconds())
+
+	return 0
+}
+
+func storeRun(path string, run output.Run, summary []lava.Summary) error {
+	st, err := store.Open(path)
+	if err != nil {
+		return err
+	}
+	defer st.Close()
+
+	return st.SaveRun(run, summary)
+}
+
+func writePostgres(ctx context.Context, cfg config.PostgresConfig, run output.Run, summary []lava.Summary) error {
+	w, err := output.NewPostgresWriter(ctx, cfg)
+	if err != nil {
+		return err
+	}
+	defer w.Close(ctx)
+
+	return w.Write(ctx, run, summary)
+}
+
+func uploadResults(ctx context.Context, logger *slog.Logger, cfg *config.Config, run output.Run, results []string) error {
+	u, err := upload.NewClient(ctx, logger, cfg.S3Cfg)
+	if err != nil {
+		return err
+	}
+	prefix := u.Prefix(run.ID, run.StartedAt)
+
+	err = u.UploadFile(cfg.OutputFilePath, path.Join(prefix, filepath.Base(cfg.OutputFilePath)))
+	if err != nil {
+		return err
+	}
+	if cfg.OutputStreamFilePath != "" {
+		// The stream file of the run is uploaded with the configured name,
+		// as the key is already scoped to the run.
+		err = u.UploadFile(run.Path(cfg.OutputStreamFilePath), path.Join(prefix, filepath.Base(cfg.OutputStreamFilePath)))
+		if err != nil {
+			return err
+		}
+	}
+	if cfg.LavaCfg.ResultsPath != "" {
+		err = u.UploadFiles(cfg.LavaCfg.ResultsPath, results, path.Join(prefix, "results"))
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
 }
 
-func pushSummaryMetrics(m *metrics.Client, s []lava.Summary) {
+func pushSummaryMetrics(m *metrics.Client, s []lava.Summary, controlsByOrg bool) {
 	sm := map[string]int{
 		"with_controls":    0,
 		"without_controls": 0,
+		"waived":           0,
 		"error":            0,
 	}
 	cm := map[string]int{}
+	pm := map[string]int{
+		policy.StatusCompliant:    0,
+		policy.StatusNonCompliant: 0,
+		policy.StatusWaived:       0,
+		policy.StatusError:        0,
+	}
+	osm := map[string]map[string]int{}
+	ocm := map[string]map[string]int{}
 	for _, s := range s {
+		pm[s.PolicyStatus]++
+		org := s.Organization()
+		if _, ok := osm[org]; !ok {
+			osm[org] = map[string]int{
+				"with_controls":    0,
+				"without_controls": 0,
+				"waived":           0,
+				"error":            0,
+			}
+			ocm[org] = map[string]int{}
+		}
 		if s.Error != "" {
 			sm["error"]++
+			osm[org]["error"]++
 			continue
 		}
-		if s.ControlInPlace {
+		switch {
+		case s.ControlInPlace:
 			sm["with_controls"]++
-		} else {
+			osm[org]["with_controls"]++
+		case s.PolicyStatus == policy.StatusWaived:
+			// Waived repositories are not reported as lacking controls.
+			sm["waived"]++
+			osm[org]["waived"]++
+		default:
 			sm["without_controls"]++
+			osm[org]["without_controls"]++
 		}
 		for _, c := range s.Controls {
 			cm[c]++
+			ocm[org][c]++
 		}
 	}
 	for k, v := range sm {
@@ -110,4 +470,90 @@ func pushSummaryMetrics(m *metrics.Client, s []lava.Summary) {
 		tags := []string{fmt.Sprintf("control:%s", k)}
 		m.Gauge("summary.controls", v, tags)
 	}
+	for k, v := range pm {
+		tags := []string{fmt.Sprintf("status:%s", k)}
+		m.Gauge("summary.policy", v, tags)
+	}
+	for org, sm := range osm {
+		for k, v := range sm {
+			tags := []string{fmt.Sprintf("target:%s", k), fmt.Sprintf("organization:%s", org)}
+			m.Gauge("summary.organization.status", v, tags)
+		}
+		if !controlsByOrg {
+			continue
+		}
+		for k, v := range ocm[org] {
+			tags := []string{fmt.Sprintf("control:%s", k), fmt.Sprintf("organization:%s", org)}
+			m.Gauge("summary.organization.controls", v, tags)
+		}
+	}
+}
+
+func pushScoreMetrics(logger *slog.Logger, m *metrics.Client, scores policy.Scores) {
+	logger.Info("enterprise compliance score", "score", scores.Enterprise)
+	m.GaugeFloat("score.enterprise", scores.Enterprise, []string{})
+	for org, score := range scores.Organizations {
+		logger.Info("organization compliance score", "organization", org, "score", score)
+		m.GaugeFloat("score.organization", score, []string{fmt.Sprintf("organization:%s", org)})
+	}
+}
+
+func pushAlertMetrics(m *metrics.Client, s []lava.Summary) {
+	am := map[string]map[string]int{}
+	for _, s := range s {
+		org := s.Organization()
+		if _, ok := am[org]; !ok {
+			am[org] = map[string]int{}
+		}
+		for k, v := range s.Alerts {
+			am[org][k] += v
+		}
+	}
+	for org, alerts := range am {
+		for k, v := range alerts {
+			tool, severity, _ := strings.Cut(k, ":")
+			tags := []string{fmt.Sprintf("tool:%s", tool), fmt.Sprintf("severity:%s", severity), fmt.Sprintf("organization:%s", org)}
+			m.Gauge("alerts.open", v, tags)
+		}
+	}
+}
+
+func pushFindingMetrics(m *metrics.Client, s []lava.Summary) {
+	fm := map[string]map[string]int{}
+	for _, s := range s {
+		org := s.Organization()
+		if _, ok := fm[org]; !ok {
+			fm[org] = map[string]int{}
+		}
+		for _, f := range s.Findings {
+			rule, rest, _ := strings.Cut(f, ":")
+			// Organization webhook findings are reported once per
+			// organization by pushOrganizationFindings.
+			if strings.HasPrefix(rest, "org/") {
+				continue
+			}
+			fm[org][rule]++
+		}
+	}
+	for org, findings := range fm {
+		for k, v := range findings {
+			tags := []string{fmt.Sprintf("rule:%s", k), fmt.Sprintf("organization:%s", org)}
+			m.Gauge("findings", v, tags)
+		}
+	}
+}
+
+func pushOrganizationFindings(logger *slog.Logger, m *metrics.Client, findings map[string][]string) {
+	for org, findings := range findings {
+		fm := map[string]int{}
+		for _, f := range findings {
+			logger.Warn("organization finding", "organization", org, "finding", f)
+			rule, _, _ := strings.Cut(f, ":")
+			fm[rule]++
+		}
+		for k, v := range fm {
+			tags := []string{fmt.Sprintf("rule:%s", k), fmt.Sprintf("organization:%s", org)}
+			m.Gauge("findings.organization", v, tags)
+		}
+	}
 }